@@ -1,7 +1,9 @@
 package types
 
 import (
+	"path"
 	"regexp"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -71,3 +73,69 @@ func (slice StringSlice) regexExists(str string) bool {
 
 	return false
 }
+
+// IsGlobExcluded determines whether or not a given string is excluded in terms of a blacklist StringSlice of glob patterns.
+// If the StringSlice is empty, then all provided strings are considered not excluded.
+// If the provided string matches any of the glob patterns, then it is considered excluded.
+func (slice StringSlice) IsGlobExcluded(str string) bool {
+	if slice.IsEmpty() {
+		return false
+	}
+
+	return slice.globExists(str)
+}
+
+// IsGlobIncluded determines whether or not a given string is included in terms of a whitelist StringSlice of glob patterns.
+// If the StringSlice is empty, then all provided strings are considered included.
+// If the StringSlice is not empty, then only strings that match any of the glob patterns will be considered included.
+func (slice StringSlice) IsGlobIncluded(str string) bool {
+	if slice.IsEmpty() {
+		return true
+	}
+
+	return slice.globExists(str)
+}
+
+func (slice StringSlice) globExists(str string) bool {
+	for _, pattern := range slice {
+		if match, err := path.Match(pattern, str); match {
+			return true
+		} else if err != nil {
+			log.WithFields(log.Fields{"pattern": pattern}).Errorf(err.Error())
+		}
+	}
+
+	return false
+}
+
+// IsPrefixExcluded determines whether or not a given string is excluded in terms of a blacklist StringSlice of glob patterns and prefixes.
+// Patterns ending with * additionally match every string starting with the rest of the pattern, including strings containing a /.
+// If the StringSlice is empty, then all provided strings are considered not excluded.
+func (slice StringSlice) IsPrefixExcluded(str string) bool {
+	if slice.IsEmpty() {
+		return false
+	}
+
+	return slice.prefixExists(str)
+}
+
+// IsPrefixIncluded determines whether or not a given string is included in terms of a whitelist StringSlice of glob patterns and prefixes.
+// Patterns ending with * additionally match every string starting with the rest of the pattern, including strings containing a /.
+// If the StringSlice is empty, then all provided strings are considered included.
+func (slice StringSlice) IsPrefixIncluded(str string) bool {
+	if slice.IsEmpty() {
+		return true
+	}
+
+	return slice.prefixExists(str)
+}
+
+func (slice StringSlice) prefixExists(str string) bool {
+	for _, pattern := range slice {
+		if strings.HasSuffix(pattern, "*") && strings.HasPrefix(str, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+
+	return slice.globExists(str)
+}
@@ -6,6 +6,15 @@ import (
 	"k8s.io/client-go/rest"
 )
 
+// Interface has methods to work with kube-secret-sync resources.
+type Interface interface {
+	SecretSyncRuleGetter
+	ConfigMapSyncRuleGetter
+	ResourceSyncRuleGetter
+	NamespacedSecretSyncRuleGetter
+	SecretSyncPolicyGetter
+}
+
 // KubeSecretSyncClient represents the REST client for kube-secret-sync
 type KubeSecretSyncClientset struct {
 	client rest.Interface
@@ -32,3 +41,19 @@ func NewForConfig(c *rest.Config) (*KubeSecretSyncClientset, error) {
 func (c *KubeSecretSyncClientset) SecretSyncRules() SecretSyncRuleInterface {
 	return newSecretSyncRules(c)
 }
+
+func (c *KubeSecretSyncClientset) ConfigMapSyncRules() ConfigMapSyncRuleInterface {
+	return newConfigMapSyncRules(c)
+}
+
+func (c *KubeSecretSyncClientset) ResourceSyncRules() ResourceSyncRuleInterface {
+	return newResourceSyncRules(c)
+}
+
+func (c *KubeSecretSyncClientset) NamespacedSecretSyncRules(namespace string) NamespacedSecretSyncRuleInterface {
+	return newNamespacedSecretSyncRules(c, namespace)
+}
+
+func (c *KubeSecretSyncClientset) SecretSyncPolicies() SecretSyncPolicyInterface {
+	return newSecretSyncPolicies(c)
+}
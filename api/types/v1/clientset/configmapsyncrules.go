@@ -0,0 +1,93 @@
+package clientset
+
+import (
+	"context"
+	"time"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+const configMapSyncRulesResource = "configmapsyncrules"
+
+// ConfigMapSyncRuleGetter has a method to return a ConfigMapSyncRuleInterface.
+type ConfigMapSyncRuleGetter interface {
+	ConfigMapSyncRules() ConfigMapSyncRuleInterface
+}
+
+// configMapSyncRules implements ConfigMapSyncRuleInterface
+type configMapSyncRules struct {
+	client rest.Interface
+}
+
+// newConfigMapSyncRules returns a ConfigMapSyncRules
+func newConfigMapSyncRules(c *KubeSecretSyncClientset) *configMapSyncRules {
+	return &configMapSyncRules{
+		client: c.client,
+	}
+}
+
+// ConfigMapSyncRuleInterface has methods to work with ConfigMapSyncRule resources.
+type ConfigMapSyncRuleInterface interface {
+	List(ctx context.Context, opts metav1.ListOptions) (*typesv1.ConfigMapSyncRuleList, error)
+	Get(ctx context.Context, name string, options metav1.GetOptions) (*typesv1.ConfigMapSyncRule, error)
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	UpdateStatus(ctx context.Context, rule *typesv1.ConfigMapSyncRule, opts metav1.UpdateOptions) (*typesv1.ConfigMapSyncRule, error)
+}
+
+func (c *configMapSyncRules) List(ctx context.Context, opts metav1.ListOptions) (*typesv1.ConfigMapSyncRuleList, error) {
+	result := typesv1.ConfigMapSyncRuleList{}
+	err := c.client.
+		Get().
+		Resource(configMapSyncRulesResource).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *configMapSyncRules) Get(ctx context.Context, name string, opts metav1.GetOptions) (*typesv1.ConfigMapSyncRule, error) {
+	result := typesv1.ConfigMapSyncRule{}
+	err := c.client.
+		Get().
+		Resource(configMapSyncRulesResource).
+		Name(name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *configMapSyncRules) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.
+		Get().
+		Resource(configMapSyncRulesResource).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+func (c *configMapSyncRules) UpdateStatus(ctx context.Context, rule *typesv1.ConfigMapSyncRule, opts metav1.UpdateOptions) (*typesv1.ConfigMapSyncRule, error) {
+	result := typesv1.ConfigMapSyncRule{}
+	err := c.client.
+		Put().
+		Resource(configMapSyncRulesResource).
+		Name(rule.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(rule).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
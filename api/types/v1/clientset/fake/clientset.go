@@ -0,0 +1,64 @@
+package fake
+
+import (
+	"github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/testing"
+)
+
+// Clientset implements clientset.Interface backed by an in-memory object tracker.
+type Clientset struct {
+	testing.Fake
+	tracker testing.ObjectTracker
+}
+
+// NewSimpleClientset returns a clientset that will respond with the provided objects.
+func NewSimpleClientset(objects ...runtime.Object) *Clientset {
+	scheme := runtime.NewScheme()
+	if err := clientset.AddToScheme(scheme); err != nil {
+		panic(err)
+	}
+
+	tracker := testing.NewObjectTracker(scheme, serializer.NewCodecFactory(scheme).UniversalDecoder())
+	for _, obj := range objects {
+		if err := tracker.Add(obj); err != nil {
+			panic(err)
+		}
+	}
+
+	c := &Clientset{tracker: tracker}
+	c.AddReactor("*", "*", testing.ObjectReaction(tracker))
+	c.AddWatchReactor("*", func(action testing.Action) (handled bool, ret watch.Interface, err error) {
+		ret, err = tracker.Watch(action.GetResource(), action.GetNamespace())
+		return true, ret, err
+	})
+
+	return c
+}
+
+// Tracker returns the object tracker backing the clientset.
+func (c *Clientset) Tracker() testing.ObjectTracker {
+	return c.tracker
+}
+
+func (c *Clientset) SecretSyncRules() clientset.SecretSyncRuleInterface {
+	return &secretSyncRules{fake: &c.Fake}
+}
+
+func (c *Clientset) ConfigMapSyncRules() clientset.ConfigMapSyncRuleInterface {
+	return &configMapSyncRules{fake: &c.Fake}
+}
+
+func (c *Clientset) ResourceSyncRules() clientset.ResourceSyncRuleInterface {
+	return &resourceSyncRules{fake: &c.Fake}
+}
+
+func (c *Clientset) NamespacedSecretSyncRules(namespace string) clientset.NamespacedSecretSyncRuleInterface {
+	return &namespacedSecretSyncRules{fake: &c.Fake, namespace: namespace}
+}
+
+func (c *Clientset) SecretSyncPolicies() clientset.SecretSyncPolicyInterface {
+	return &secretSyncPolicies{fake: &c.Fake}
+}
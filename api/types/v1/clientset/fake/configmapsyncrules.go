@@ -0,0 +1,60 @@
+package fake
+
+import (
+	"context"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/testing"
+)
+
+var configMapSyncRulesResource = clientset.SchemeGroupVersion.WithResource("configmapsyncrules")
+
+var configMapSyncRulesKind = clientset.SchemeGroupVersion.WithKind(clientset.ConfigMapSyncRule)
+
+// configMapSyncRules implements clientset.ConfigMapSyncRuleInterface
+type configMapSyncRules struct {
+	fake *testing.Fake
+}
+
+func (c *configMapSyncRules) List(ctx context.Context, opts metav1.ListOptions) (*typesv1.ConfigMapSyncRuleList, error) {
+	obj, err := c.fake.Invokes(testing.NewRootListAction(configMapSyncRulesResource, configMapSyncRulesKind, opts), &typesv1.ConfigMapSyncRuleList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &typesv1.ConfigMapSyncRuleList{ListMeta: obj.(*typesv1.ConfigMapSyncRuleList).ListMeta}
+	for _, item := range obj.(*typesv1.ConfigMapSyncRuleList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+func (c *configMapSyncRules) Get(ctx context.Context, name string, opts metav1.GetOptions) (*typesv1.ConfigMapSyncRule, error) {
+	obj, err := c.fake.Invokes(testing.NewRootGetAction(configMapSyncRulesResource, name), &typesv1.ConfigMapSyncRule{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*typesv1.ConfigMapSyncRule), err
+}
+
+func (c *configMapSyncRules) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	return c.fake.InvokesWatch(testing.NewRootWatchAction(configMapSyncRulesResource, opts))
+}
+
+func (c *configMapSyncRules) UpdateStatus(ctx context.Context, rule *typesv1.ConfigMapSyncRule, opts metav1.UpdateOptions) (*typesv1.ConfigMapSyncRule, error) {
+	obj, err := c.fake.Invokes(testing.NewRootUpdateSubresourceAction(configMapSyncRulesResource, "status", rule), &typesv1.ConfigMapSyncRule{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*typesv1.ConfigMapSyncRule), err
+}
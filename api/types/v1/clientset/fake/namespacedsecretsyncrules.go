@@ -0,0 +1,78 @@
+package fake
+
+import (
+	"context"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/testing"
+)
+
+var namespacedSecretSyncRulesResource = clientset.SchemeGroupVersion.WithResource("namespacedsecretsyncrules")
+
+var namespacedSecretSyncRulesKind = clientset.SchemeGroupVersion.WithKind(clientset.NamespacedSecretSyncRule)
+
+// namespacedSecretSyncRules implements clientset.NamespacedSecretSyncRuleInterface
+type namespacedSecretSyncRules struct {
+	fake      *testing.Fake
+	namespace string
+}
+
+func (c *namespacedSecretSyncRules) List(ctx context.Context, opts metav1.ListOptions) (*typesv1.NamespacedSecretSyncRuleList, error) {
+	obj, err := c.fake.Invokes(testing.NewListAction(namespacedSecretSyncRulesResource, namespacedSecretSyncRulesKind, c.namespace, opts), &typesv1.NamespacedSecretSyncRuleList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &typesv1.NamespacedSecretSyncRuleList{ListMeta: obj.(*typesv1.NamespacedSecretSyncRuleList).ListMeta}
+	for _, item := range obj.(*typesv1.NamespacedSecretSyncRuleList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+func (c *namespacedSecretSyncRules) Get(ctx context.Context, name string, opts metav1.GetOptions) (*typesv1.NamespacedSecretSyncRule, error) {
+	obj, err := c.fake.Invokes(testing.NewGetAction(namespacedSecretSyncRulesResource, c.namespace, name), &typesv1.NamespacedSecretSyncRule{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*typesv1.NamespacedSecretSyncRule), err
+}
+
+func (c *namespacedSecretSyncRules) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	return c.fake.InvokesWatch(testing.NewWatchAction(namespacedSecretSyncRulesResource, c.namespace, opts))
+}
+
+func (c *namespacedSecretSyncRules) Update(ctx context.Context, rule *typesv1.NamespacedSecretSyncRule, opts metav1.UpdateOptions) (*typesv1.NamespacedSecretSyncRule, error) {
+	obj, err := c.fake.Invokes(testing.NewUpdateAction(namespacedSecretSyncRulesResource, c.namespace, rule), &typesv1.NamespacedSecretSyncRule{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*typesv1.NamespacedSecretSyncRule), err
+}
+
+func (c *namespacedSecretSyncRules) UpdateStatus(ctx context.Context, rule *typesv1.NamespacedSecretSyncRule, opts metav1.UpdateOptions) (*typesv1.NamespacedSecretSyncRule, error) {
+	obj, err := c.fake.Invokes(testing.NewUpdateSubresourceAction(namespacedSecretSyncRulesResource, "status", c.namespace, rule), &typesv1.NamespacedSecretSyncRule{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*typesv1.NamespacedSecretSyncRule), err
+}
+
+func (c *namespacedSecretSyncRules) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*typesv1.NamespacedSecretSyncRule, error) {
+	obj, err := c.fake.Invokes(testing.NewPatchSubresourceAction(namespacedSecretSyncRulesResource, c.namespace, name, pt, data, subresources...), &typesv1.NamespacedSecretSyncRule{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*typesv1.NamespacedSecretSyncRule), err
+}
@@ -0,0 +1,60 @@
+package fake
+
+import (
+	"context"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/testing"
+)
+
+var resourceSyncRulesResource = clientset.SchemeGroupVersion.WithResource("resourcesyncrules")
+
+var resourceSyncRulesKind = clientset.SchemeGroupVersion.WithKind(clientset.ResourceSyncRule)
+
+// resourceSyncRules implements clientset.ResourceSyncRuleInterface
+type resourceSyncRules struct {
+	fake *testing.Fake
+}
+
+func (c *resourceSyncRules) List(ctx context.Context, opts metav1.ListOptions) (*typesv1.ResourceSyncRuleList, error) {
+	obj, err := c.fake.Invokes(testing.NewRootListAction(resourceSyncRulesResource, resourceSyncRulesKind, opts), &typesv1.ResourceSyncRuleList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &typesv1.ResourceSyncRuleList{ListMeta: obj.(*typesv1.ResourceSyncRuleList).ListMeta}
+	for _, item := range obj.(*typesv1.ResourceSyncRuleList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+func (c *resourceSyncRules) Get(ctx context.Context, name string, opts metav1.GetOptions) (*typesv1.ResourceSyncRule, error) {
+	obj, err := c.fake.Invokes(testing.NewRootGetAction(resourceSyncRulesResource, name), &typesv1.ResourceSyncRule{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*typesv1.ResourceSyncRule), err
+}
+
+func (c *resourceSyncRules) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	return c.fake.InvokesWatch(testing.NewRootWatchAction(resourceSyncRulesResource, opts))
+}
+
+func (c *resourceSyncRules) UpdateStatus(ctx context.Context, rule *typesv1.ResourceSyncRule, opts metav1.UpdateOptions) (*typesv1.ResourceSyncRule, error) {
+	obj, err := c.fake.Invokes(testing.NewRootUpdateSubresourceAction(resourceSyncRulesResource, "status", rule), &typesv1.ResourceSyncRule{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*typesv1.ResourceSyncRule), err
+}
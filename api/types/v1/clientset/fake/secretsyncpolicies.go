@@ -0,0 +1,52 @@
+package fake
+
+import (
+	"context"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/testing"
+)
+
+var secretSyncPoliciesResource = clientset.SchemeGroupVersion.WithResource("secretsyncpolicies")
+
+var secretSyncPoliciesKind = clientset.SchemeGroupVersion.WithKind(clientset.SecretSyncPolicy)
+
+// secretSyncPolicies implements clientset.SecretSyncPolicyInterface
+type secretSyncPolicies struct {
+	fake *testing.Fake
+}
+
+func (c *secretSyncPolicies) List(ctx context.Context, opts metav1.ListOptions) (*typesv1.SecretSyncPolicyList, error) {
+	obj, err := c.fake.Invokes(testing.NewRootListAction(secretSyncPoliciesResource, secretSyncPoliciesKind, opts), &typesv1.SecretSyncPolicyList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &typesv1.SecretSyncPolicyList{ListMeta: obj.(*typesv1.SecretSyncPolicyList).ListMeta}
+	for _, item := range obj.(*typesv1.SecretSyncPolicyList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+func (c *secretSyncPolicies) Get(ctx context.Context, name string, opts metav1.GetOptions) (*typesv1.SecretSyncPolicy, error) {
+	obj, err := c.fake.Invokes(testing.NewRootGetAction(secretSyncPoliciesResource, name), &typesv1.SecretSyncPolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*typesv1.SecretSyncPolicy), err
+}
+
+func (c *secretSyncPolicies) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	return c.fake.InvokesWatch(testing.NewRootWatchAction(secretSyncPoliciesResource, opts))
+}
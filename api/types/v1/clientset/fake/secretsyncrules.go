@@ -0,0 +1,77 @@
+package fake
+
+import (
+	"context"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/testing"
+)
+
+var secretSyncRulesResource = clientset.SchemeGroupVersion.WithResource("secretsyncrules")
+
+var secretSyncRulesKind = clientset.SchemeGroupVersion.WithKind(clientset.SecretSyncRule)
+
+// secretSyncRules implements clientset.SecretSyncRuleInterface
+type secretSyncRules struct {
+	fake *testing.Fake
+}
+
+func (c *secretSyncRules) List(ctx context.Context, opts metav1.ListOptions) (*typesv1.SecretSyncRuleList, error) {
+	obj, err := c.fake.Invokes(testing.NewRootListAction(secretSyncRulesResource, secretSyncRulesKind, opts), &typesv1.SecretSyncRuleList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &typesv1.SecretSyncRuleList{ListMeta: obj.(*typesv1.SecretSyncRuleList).ListMeta}
+	for _, item := range obj.(*typesv1.SecretSyncRuleList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+func (c *secretSyncRules) Get(ctx context.Context, name string, opts metav1.GetOptions) (*typesv1.SecretSyncRule, error) {
+	obj, err := c.fake.Invokes(testing.NewRootGetAction(secretSyncRulesResource, name), &typesv1.SecretSyncRule{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*typesv1.SecretSyncRule), err
+}
+
+func (c *secretSyncRules) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	return c.fake.InvokesWatch(testing.NewRootWatchAction(secretSyncRulesResource, opts))
+}
+
+func (c *secretSyncRules) Update(ctx context.Context, rule *typesv1.SecretSyncRule, opts metav1.UpdateOptions) (*typesv1.SecretSyncRule, error) {
+	obj, err := c.fake.Invokes(testing.NewRootUpdateAction(secretSyncRulesResource, rule), &typesv1.SecretSyncRule{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*typesv1.SecretSyncRule), err
+}
+
+func (c *secretSyncRules) UpdateStatus(ctx context.Context, rule *typesv1.SecretSyncRule, opts metav1.UpdateOptions) (*typesv1.SecretSyncRule, error) {
+	obj, err := c.fake.Invokes(testing.NewRootUpdateSubresourceAction(secretSyncRulesResource, "status", rule), &typesv1.SecretSyncRule{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*typesv1.SecretSyncRule), err
+}
+
+func (c *secretSyncRules) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*typesv1.SecretSyncRule, error) {
+	obj, err := c.fake.Invokes(testing.NewRootPatchSubresourceAction(secretSyncRulesResource, name, pt, data, subresources...), &typesv1.SecretSyncRule{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*typesv1.SecretSyncRule), err
+}
@@ -0,0 +1,97 @@
+package clientset
+
+import (
+	"context"
+	"time"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NewSecretSyncRuleInformer constructs a new shared informer for SecretSyncRule resources.
+func NewSecretSyncRuleInformer(client Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return client.SecretSyncRules().List(context.Background(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return client.SecretSyncRules().Watch(context.Background(), options)
+			},
+		},
+		&typesv1.SecretSyncRule{},
+		resyncPeriod,
+		cache.Indexers{},
+	)
+}
+
+// NewConfigMapSyncRuleInformer constructs a new shared informer for ConfigMapSyncRule resources.
+func NewConfigMapSyncRuleInformer(client Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return client.ConfigMapSyncRules().List(context.Background(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return client.ConfigMapSyncRules().Watch(context.Background(), options)
+			},
+		},
+		&typesv1.ConfigMapSyncRule{},
+		resyncPeriod,
+		cache.Indexers{},
+	)
+}
+
+// NewResourceSyncRuleInformer constructs a new shared informer for ResourceSyncRule resources.
+func NewResourceSyncRuleInformer(client Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return client.ResourceSyncRules().List(context.Background(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return client.ResourceSyncRules().Watch(context.Background(), options)
+			},
+		},
+		&typesv1.ResourceSyncRule{},
+		resyncPeriod,
+		cache.Indexers{},
+	)
+}
+
+// NewNamespacedSecretSyncRuleInformer constructs a new shared informer for NamespacedSecretSyncRule resources in all namespaces.
+func NewNamespacedSecretSyncRuleInformer(client Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return client.NamespacedSecretSyncRules(metav1.NamespaceAll).List(context.Background(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return client.NamespacedSecretSyncRules(metav1.NamespaceAll).Watch(context.Background(), options)
+			},
+		},
+		&typesv1.NamespacedSecretSyncRule{},
+		resyncPeriod,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+	)
+}
+
+// NewSecretSyncPolicyInformer constructs a new shared informer for SecretSyncPolicy resources.
+func NewSecretSyncPolicyInformer(client Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return client.SecretSyncPolicies().List(context.Background(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return client.SecretSyncPolicies().Watch(context.Background(), options)
+			},
+		},
+		&typesv1.SecretSyncPolicy{},
+		resyncPeriod,
+		cache.Indexers{},
+	)
+}
@@ -0,0 +1,172 @@
+package clientset
+
+import (
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// SecretSyncRuleLister helps list SecretSyncRules from a shared informer's cache.
+// All objects returned here must be treated as read-only.
+type SecretSyncRuleLister interface {
+	List(selector labels.Selector) ([]*typesv1.SecretSyncRule, error)
+	Get(name string) (*typesv1.SecretSyncRule, error)
+}
+
+// secretSyncRuleLister implements SecretSyncRuleLister
+type secretSyncRuleLister struct {
+	indexer cache.Indexer
+}
+
+// NewSecretSyncRuleLister returns a new SecretSyncRuleLister backed by the given indexer.
+func NewSecretSyncRuleLister(indexer cache.Indexer) SecretSyncRuleLister {
+	return &secretSyncRuleLister{indexer: indexer}
+}
+
+func (l *secretSyncRuleLister) List(selector labels.Selector) (rules []*typesv1.SecretSyncRule, err error) {
+	err = cache.ListAll(l.indexer, selector, func(obj interface{}) {
+		rules = append(rules, obj.(*typesv1.SecretSyncRule))
+	})
+	return
+}
+
+func (l *secretSyncRuleLister) Get(name string) (*typesv1.SecretSyncRule, error) {
+	obj, exists, err := l.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: GroupName, Resource: secretSyncRulesResource}, name)
+	}
+	return obj.(*typesv1.SecretSyncRule), nil
+}
+
+// ConfigMapSyncRuleLister helps list ConfigMapSyncRules from a shared informer's cache.
+// All objects returned here must be treated as read-only.
+type ConfigMapSyncRuleLister interface {
+	List(selector labels.Selector) ([]*typesv1.ConfigMapSyncRule, error)
+	Get(name string) (*typesv1.ConfigMapSyncRule, error)
+}
+
+// configMapSyncRuleLister implements ConfigMapSyncRuleLister
+type configMapSyncRuleLister struct {
+	indexer cache.Indexer
+}
+
+// NewConfigMapSyncRuleLister returns a new ConfigMapSyncRuleLister backed by the given indexer.
+func NewConfigMapSyncRuleLister(indexer cache.Indexer) ConfigMapSyncRuleLister {
+	return &configMapSyncRuleLister{indexer: indexer}
+}
+
+func (l *configMapSyncRuleLister) List(selector labels.Selector) (rules []*typesv1.ConfigMapSyncRule, err error) {
+	err = cache.ListAll(l.indexer, selector, func(obj interface{}) {
+		rules = append(rules, obj.(*typesv1.ConfigMapSyncRule))
+	})
+	return
+}
+
+func (l *configMapSyncRuleLister) Get(name string) (*typesv1.ConfigMapSyncRule, error) {
+	obj, exists, err := l.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: GroupName, Resource: configMapSyncRulesResource}, name)
+	}
+	return obj.(*typesv1.ConfigMapSyncRule), nil
+}
+
+// ResourceSyncRuleLister helps list ResourceSyncRules from a shared informer's cache.
+// All objects returned here must be treated as read-only.
+type ResourceSyncRuleLister interface {
+	List(selector labels.Selector) ([]*typesv1.ResourceSyncRule, error)
+	Get(name string) (*typesv1.ResourceSyncRule, error)
+}
+
+// resourceSyncRuleLister implements ResourceSyncRuleLister
+type resourceSyncRuleLister struct {
+	indexer cache.Indexer
+}
+
+// NewResourceSyncRuleLister returns a new ResourceSyncRuleLister backed by the given indexer.
+func NewResourceSyncRuleLister(indexer cache.Indexer) ResourceSyncRuleLister {
+	return &resourceSyncRuleLister{indexer: indexer}
+}
+
+func (l *resourceSyncRuleLister) List(selector labels.Selector) (rules []*typesv1.ResourceSyncRule, err error) {
+	err = cache.ListAll(l.indexer, selector, func(obj interface{}) {
+		rules = append(rules, obj.(*typesv1.ResourceSyncRule))
+	})
+	return
+}
+
+func (l *resourceSyncRuleLister) Get(name string) (*typesv1.ResourceSyncRule, error) {
+	obj, exists, err := l.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: GroupName, Resource: resourceSyncRulesResource}, name)
+	}
+	return obj.(*typesv1.ResourceSyncRule), nil
+}
+
+// NamespacedSecretSyncRuleLister helps list NamespacedSecretSyncRules from a shared informer's cache.
+// All objects returned here must be treated as read-only.
+type NamespacedSecretSyncRuleLister interface {
+	List(selector labels.Selector) ([]*typesv1.NamespacedSecretSyncRule, error)
+	Get(namespace, name string) (*typesv1.NamespacedSecretSyncRule, error)
+}
+
+// namespacedSecretSyncRuleLister implements NamespacedSecretSyncRuleLister
+type namespacedSecretSyncRuleLister struct {
+	indexer cache.Indexer
+}
+
+// NewNamespacedSecretSyncRuleLister returns a new NamespacedSecretSyncRuleLister backed by the given indexer.
+func NewNamespacedSecretSyncRuleLister(indexer cache.Indexer) NamespacedSecretSyncRuleLister {
+	return &namespacedSecretSyncRuleLister{indexer: indexer}
+}
+
+func (l *namespacedSecretSyncRuleLister) List(selector labels.Selector) (rules []*typesv1.NamespacedSecretSyncRule, err error) {
+	err = cache.ListAll(l.indexer, selector, func(obj interface{}) {
+		rules = append(rules, obj.(*typesv1.NamespacedSecretSyncRule))
+	})
+	return
+}
+
+func (l *namespacedSecretSyncRuleLister) Get(namespace, name string) (*typesv1.NamespacedSecretSyncRule, error) {
+	obj, exists, err := l.indexer.GetByKey(namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: GroupName, Resource: namespacedSecretSyncRulesResource}, name)
+	}
+	return obj.(*typesv1.NamespacedSecretSyncRule), nil
+}
+
+// SecretSyncPolicyLister helps list SecretSyncPolicies from a shared informer's cache.
+// All objects returned here must be treated as read-only.
+type SecretSyncPolicyLister interface {
+	List(selector labels.Selector) ([]*typesv1.SecretSyncPolicy, error)
+}
+
+// secretSyncPolicyLister implements SecretSyncPolicyLister
+type secretSyncPolicyLister struct {
+	indexer cache.Indexer
+}
+
+// NewSecretSyncPolicyLister returns a new SecretSyncPolicyLister backed by the given indexer.
+func NewSecretSyncPolicyLister(indexer cache.Indexer) SecretSyncPolicyLister {
+	return &secretSyncPolicyLister{indexer: indexer}
+}
+
+func (l *secretSyncPolicyLister) List(selector labels.Selector) (policies []*typesv1.SecretSyncPolicy, err error) {
+	err = cache.ListAll(l.indexer, selector, func(obj interface{}) {
+		policies = append(policies, obj.(*typesv1.SecretSyncPolicy))
+	})
+	return
+}
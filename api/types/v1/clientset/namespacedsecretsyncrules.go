@@ -0,0 +1,133 @@
+package clientset
+
+import (
+	"context"
+	"time"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+const namespacedSecretSyncRulesResource = "namespacedsecretsyncrules"
+
+// NamespacedSecretSyncRuleGetter has a method to return a NamespacedSecretSyncRuleInterface.
+type NamespacedSecretSyncRuleGetter interface {
+	NamespacedSecretSyncRules(namespace string) NamespacedSecretSyncRuleInterface
+}
+
+// namespacedSecretSyncRules implements NamespacedSecretSyncRuleInterface
+type namespacedSecretSyncRules struct {
+	client    rest.Interface
+	namespace string
+}
+
+// newNamespacedSecretSyncRules returns a NamespacedSecretSyncRules in the given namespace
+func newNamespacedSecretSyncRules(c *KubeSecretSyncClientset, namespace string) *namespacedSecretSyncRules {
+	return &namespacedSecretSyncRules{
+		client:    c.client,
+		namespace: namespace,
+	}
+}
+
+// NamespacedSecretSyncRuleInterface has methods to work with NamespacedSecretSyncRule resources.
+type NamespacedSecretSyncRuleInterface interface {
+	List(ctx context.Context, opts metav1.ListOptions) (*typesv1.NamespacedSecretSyncRuleList, error)
+	Get(ctx context.Context, name string, options metav1.GetOptions) (*typesv1.NamespacedSecretSyncRule, error)
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	Update(ctx context.Context, rule *typesv1.NamespacedSecretSyncRule, opts metav1.UpdateOptions) (*typesv1.NamespacedSecretSyncRule, error)
+	UpdateStatus(ctx context.Context, rule *typesv1.NamespacedSecretSyncRule, opts metav1.UpdateOptions) (*typesv1.NamespacedSecretSyncRule, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*typesv1.NamespacedSecretSyncRule, error)
+}
+
+func (c *namespacedSecretSyncRules) List(ctx context.Context, opts metav1.ListOptions) (*typesv1.NamespacedSecretSyncRuleList, error) {
+	result := typesv1.NamespacedSecretSyncRuleList{}
+	err := c.client.
+		Get().
+		Namespace(c.namespace).
+		Resource(namespacedSecretSyncRulesResource).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *namespacedSecretSyncRules) Get(ctx context.Context, name string, opts metav1.GetOptions) (*typesv1.NamespacedSecretSyncRule, error) {
+	result := typesv1.NamespacedSecretSyncRule{}
+	err := c.client.
+		Get().
+		Namespace(c.namespace).
+		Resource(namespacedSecretSyncRulesResource).
+		Name(name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *namespacedSecretSyncRules) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.
+		Get().
+		Namespace(c.namespace).
+		Resource(namespacedSecretSyncRulesResource).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+func (c *namespacedSecretSyncRules) Update(ctx context.Context, rule *typesv1.NamespacedSecretSyncRule, opts metav1.UpdateOptions) (*typesv1.NamespacedSecretSyncRule, error) {
+	result := typesv1.NamespacedSecretSyncRule{}
+	err := c.client.
+		Put().
+		Namespace(c.namespace).
+		Resource(namespacedSecretSyncRulesResource).
+		Name(rule.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(rule).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *namespacedSecretSyncRules) UpdateStatus(ctx context.Context, rule *typesv1.NamespacedSecretSyncRule, opts metav1.UpdateOptions) (*typesv1.NamespacedSecretSyncRule, error) {
+	result := typesv1.NamespacedSecretSyncRule{}
+	err := c.client.
+		Put().
+		Namespace(c.namespace).
+		Resource(namespacedSecretSyncRulesResource).
+		Name(rule.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(rule).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *namespacedSecretSyncRules) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*typesv1.NamespacedSecretSyncRule, error) {
+	result := typesv1.NamespacedSecretSyncRule{}
+	err := c.client.
+		Patch(pt).
+		Namespace(c.namespace).
+		Resource(namespacedSecretSyncRulesResource).
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
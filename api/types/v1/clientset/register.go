@@ -10,6 +10,10 @@ import (
 const GroupName = "kube-secret-sync.io"
 const GroupVersion = "v1"
 const SecretSyncRule = "SecretSyncRule"
+const ConfigMapSyncRule = "ConfigMapSyncRule"
+const ResourceSyncRule = "ResourceSyncRule"
+const NamespacedSecretSyncRule = "NamespacedSecretSyncRule"
+const SecretSyncPolicy = "SecretSyncPolicy"
 
 var SchemeGroupVersion = schema.GroupVersion{Group: GroupName, Version: GroupVersion}
 
@@ -22,6 +26,14 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&v1.SecretSyncRule{},
 		&v1.SecretSyncRuleList{},
+		&v1.ConfigMapSyncRule{},
+		&v1.ConfigMapSyncRuleList{},
+		&v1.ResourceSyncRule{},
+		&v1.ResourceSyncRuleList{},
+		&v1.NamespacedSecretSyncRule{},
+		&v1.NamespacedSecretSyncRuleList{},
+		&v1.SecretSyncPolicy{},
+		&v1.SecretSyncPolicyList{},
 	)
 
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
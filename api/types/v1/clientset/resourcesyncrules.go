@@ -0,0 +1,93 @@
+package clientset
+
+import (
+	"context"
+	"time"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+const resourceSyncRulesResource = "resourcesyncrules"
+
+// ResourceSyncRuleGetter has a method to return a ResourceSyncRuleInterface.
+type ResourceSyncRuleGetter interface {
+	ResourceSyncRules() ResourceSyncRuleInterface
+}
+
+// resourceSyncRules implements ResourceSyncRuleInterface
+type resourceSyncRules struct {
+	client rest.Interface
+}
+
+// newResourceSyncRules returns a ResourceSyncRules
+func newResourceSyncRules(c *KubeSecretSyncClientset) *resourceSyncRules {
+	return &resourceSyncRules{
+		client: c.client,
+	}
+}
+
+// ResourceSyncRuleInterface has methods to work with ResourceSyncRule resources.
+type ResourceSyncRuleInterface interface {
+	List(ctx context.Context, opts metav1.ListOptions) (*typesv1.ResourceSyncRuleList, error)
+	Get(ctx context.Context, name string, options metav1.GetOptions) (*typesv1.ResourceSyncRule, error)
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	UpdateStatus(ctx context.Context, rule *typesv1.ResourceSyncRule, opts metav1.UpdateOptions) (*typesv1.ResourceSyncRule, error)
+}
+
+func (c *resourceSyncRules) List(ctx context.Context, opts metav1.ListOptions) (*typesv1.ResourceSyncRuleList, error) {
+	result := typesv1.ResourceSyncRuleList{}
+	err := c.client.
+		Get().
+		Resource(resourceSyncRulesResource).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *resourceSyncRules) Get(ctx context.Context, name string, opts metav1.GetOptions) (*typesv1.ResourceSyncRule, error) {
+	result := typesv1.ResourceSyncRule{}
+	err := c.client.
+		Get().
+		Resource(resourceSyncRulesResource).
+		Name(name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *resourceSyncRules) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.
+		Get().
+		Resource(resourceSyncRulesResource).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+func (c *resourceSyncRules) UpdateStatus(ctx context.Context, rule *typesv1.ResourceSyncRule, opts metav1.UpdateOptions) (*typesv1.ResourceSyncRule, error) {
+	result := typesv1.ResourceSyncRule{}
+	err := c.client.
+		Put().
+		Resource(resourceSyncRulesResource).
+		Name(rule.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(rule).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
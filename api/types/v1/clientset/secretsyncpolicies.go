@@ -0,0 +1,77 @@
+package clientset
+
+import (
+	"context"
+	"time"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+const secretSyncPoliciesResource = "secretsyncpolicies"
+
+// SecretSyncPolicyGetter has a method to return a SecretSyncPolicyInterface.
+type SecretSyncPolicyGetter interface {
+	SecretSyncPolicies() SecretSyncPolicyInterface
+}
+
+// secretSyncPolicies implements SecretSyncPolicyInterface
+type secretSyncPolicies struct {
+	client rest.Interface
+}
+
+// newSecretSyncPolicies returns a SecretSyncPolicies
+func newSecretSyncPolicies(c *KubeSecretSyncClientset) *secretSyncPolicies {
+	return &secretSyncPolicies{
+		client: c.client,
+	}
+}
+
+// SecretSyncPolicyInterface has methods to work with SecretSyncPolicy resources.
+type SecretSyncPolicyInterface interface {
+	List(ctx context.Context, opts metav1.ListOptions) (*typesv1.SecretSyncPolicyList, error)
+	Get(ctx context.Context, name string, options metav1.GetOptions) (*typesv1.SecretSyncPolicy, error)
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+}
+
+func (c *secretSyncPolicies) List(ctx context.Context, opts metav1.ListOptions) (*typesv1.SecretSyncPolicyList, error) {
+	result := typesv1.SecretSyncPolicyList{}
+	err := c.client.
+		Get().
+		Resource(secretSyncPoliciesResource).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *secretSyncPolicies) Get(ctx context.Context, name string, opts metav1.GetOptions) (*typesv1.SecretSyncPolicy, error) {
+	result := typesv1.SecretSyncPolicy{}
+	err := c.client.
+		Get().
+		Resource(secretSyncPoliciesResource).
+		Name(name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *secretSyncPolicies) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.
+		Get().
+		Resource(secretSyncPoliciesResource).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
@@ -6,12 +6,13 @@ import (
 
 	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 )
 
-const resource = "secretsyncrules"
+const secretSyncRulesResource = "secretsyncrules"
 
 // SecretSyncRuleGetter has a method to return a SecretSyncRuleInterface.
 type SecretSyncRuleGetter interface {
@@ -35,13 +36,16 @@ type SecretSyncRuleInterface interface {
 	List(ctx context.Context, opts metav1.ListOptions) (*typesv1.SecretSyncRuleList, error)
 	Get(ctx context.Context, name string, options metav1.GetOptions) (*typesv1.SecretSyncRule, error)
 	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	Update(ctx context.Context, rule *typesv1.SecretSyncRule, opts metav1.UpdateOptions) (*typesv1.SecretSyncRule, error)
+	UpdateStatus(ctx context.Context, rule *typesv1.SecretSyncRule, opts metav1.UpdateOptions) (*typesv1.SecretSyncRule, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*typesv1.SecretSyncRule, error)
 }
 
 func (c *secretSyncRules) List(ctx context.Context, opts metav1.ListOptions) (*typesv1.SecretSyncRuleList, error) {
 	result := typesv1.SecretSyncRuleList{}
 	err := c.client.
 		Get().
-		Resource(resource).
+		Resource(secretSyncRulesResource).
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Do(ctx).
 		Into(&result)
@@ -53,7 +57,7 @@ func (c *secretSyncRules) Get(ctx context.Context, name string, opts metav1.GetO
 	result := typesv1.SecretSyncRule{}
 	err := c.client.
 		Get().
-		Resource(resource).
+		Resource(secretSyncRulesResource).
 		Name(name).
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Do(ctx).
@@ -70,8 +74,52 @@ func (c *secretSyncRules) Watch(ctx context.Context, opts metav1.ListOptions) (w
 	opts.Watch = true
 	return c.client.
 		Get().
-		Resource(resource).
+		Resource(secretSyncRulesResource).
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
 		Watch(ctx)
 }
+
+func (c *secretSyncRules) Update(ctx context.Context, rule *typesv1.SecretSyncRule, opts metav1.UpdateOptions) (*typesv1.SecretSyncRule, error) {
+	result := typesv1.SecretSyncRule{}
+	err := c.client.
+		Put().
+		Resource(secretSyncRulesResource).
+		Name(rule.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(rule).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *secretSyncRules) UpdateStatus(ctx context.Context, rule *typesv1.SecretSyncRule, opts metav1.UpdateOptions) (*typesv1.SecretSyncRule, error) {
+	result := typesv1.SecretSyncRule{}
+	err := c.client.
+		Put().
+		Resource(secretSyncRulesResource).
+		Name(rule.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(rule).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *secretSyncRules) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*typesv1.SecretSyncRule, error) {
+	result := typesv1.SecretSyncRule{}
+	err := c.client.
+		Patch(pt).
+		Resource(secretSyncRulesResource).
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
@@ -0,0 +1,116 @@
+package v1
+
+import (
+	"github.com/alehechka/kube-secret-sync/api/types"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// ConfigMapSyncRule is the definition for the ConfigMapSyncRule CRD
+type ConfigMapSyncRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ConfigMapSyncRuleSpec `json:"spec"`
+	Status SecretSyncRuleStatus  `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ConfigMapSyncRuleList is the definition for the ConfigMapSyncRule CRD list
+type ConfigMapSyncRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ConfigMapSyncRule `json:"items"`
+}
+
+// +kubebuilder:object:generate=true
+
+// ConfigMapSyncRuleSpec is the spec attribute of the ConfigMapSyncRule CRD
+//
+// The ConfigMaps to sync are the union of ConfigMap and every reference in ConfigMaps.
+type ConfigMapSyncRuleSpec struct {
+	ConfigMap  ConfigMap      `json:"configMap,omitempty"`
+	ConfigMaps []ConfigMap    `json:"configMaps,omitempty"`
+	Rules      ConfigMapRules `json:"rules"`
+}
+
+// +kubebuilder:object:generate=true
+
+// ConfigMapRules contains all rules for the ConfigMap to follow
+//
+// Label, annotation and conflict rules only apply to Secrets and are therefore not part of ConfigMapRules.
+type ConfigMapRules struct {
+	Namespaces NamespaceRules `json:"namespaces"`
+	Keys       KeyRules       `json:"keys,omitempty"`
+	Force      bool           `json:"force"`
+}
+
+// ShouldSyncNamespace determines whether or not the given Namespace should be synced to from the given source namespaces
+func (rules *ConfigMapRules) ShouldSyncNamespace(namespace *v1.Namespace, sourceNamespaces types.StringSlice) bool {
+	return shouldSyncNamespace(&rules.Namespaces, namespace, sourceNamespaces)
+}
+
+// +kubebuilder:object:generate=true
+
+// ConfigMap defines the attributes of the ConfigMap to sync
+type ConfigMap struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// ConfigMapReferences returns every ConfigMap referenced by the spec
+func (spec *ConfigMapSyncRuleSpec) ConfigMapReferences() (references []ConfigMap) {
+	if spec.ConfigMap.Name != "" {
+		references = append(references, spec.ConfigMap)
+	}
+
+	return append(references, spec.ConfigMaps...)
+}
+
+// SourceNamespaces returns every namespace that the spec syncs ConfigMaps from
+func (spec *ConfigMapSyncRuleSpec) SourceNamespaces() types.StringSlice {
+	namespaces := sets.NewString()
+
+	for _, reference := range spec.ConfigMapReferences() {
+		namespaces.Insert(reference.Namespace)
+	}
+
+	return namespaces.List()
+}
+
+// ShouldSyncConfigMap determines whether or not the given ConfigMap should be synced
+func (rule *ConfigMapSyncRule) ShouldSyncConfigMap(configMap *v1.ConfigMap) bool {
+	for _, reference := range rule.Spec.ConfigMapReferences() {
+		if reference.Name == configMap.Name && reference.Namespace == configMap.Namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ShouldSyncNamespace determines whether or not the given Namespace should be synced
+func (rule *ConfigMapSyncRule) ShouldSyncNamespace(namespace *v1.Namespace) bool {
+	return rule.matchesNamespace(namespace) && AcceptsRule(namespace, rule.Name, rule.Spec.Rules.Namespaces)
+}
+
+func (rule *ConfigMapSyncRule) matchesNamespace(namespace *v1.Namespace) bool {
+	return rule.Spec.Rules.ShouldSyncNamespace(namespace, rule.Spec.SourceNamespaces())
+}
+
+// Namespaces returns a list of all cached namespaces that the given Rule allows for syncing
+func (rule *ConfigMapSyncRule) Namespaces(lister corelisters.NamespaceLister) []*v1.Namespace {
+	return filterNamespaces(lister, rule.ShouldSyncNamespace)
+}
+
+// RefusedNamespaces returns a list of all cached namespaces that the given Rule matches but whose annotations refuse it
+func (rule *ConfigMapSyncRule) RefusedNamespaces(lister corelisters.NamespaceLister) []*v1.Namespace {
+	return refusedNamespaces(lister, rule.matchesNamespace, rule.Name, rule.Spec.Rules.Namespaces)
+}
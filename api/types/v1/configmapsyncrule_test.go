@@ -0,0 +1,35 @@
+package v1_test
+
+import (
+	"testing"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func configMapRule(namespaces typesv1.NamespaceRules) *typesv1.ConfigMapSyncRule {
+	return &typesv1.ConfigMapSyncRule{Spec: typesv1.ConfigMapSyncRuleSpec{
+		ConfigMap:  typesv1.ConfigMap{Name: "ca-bundle", Namespace: "source"},
+		ConfigMaps: []typesv1.ConfigMap{{Name: "shared", Namespace: "config"}},
+		Rules:      typesv1.ConfigMapRules{Namespaces: namespaces},
+	}}
+}
+
+func Test_ShouldSyncConfigMap(t *testing.T) {
+	r := configMapRule(typesv1.NamespaceRules{})
+
+	assert.True(t, r.ShouldSyncConfigMap(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "ca-bundle", Namespace: "source"}}))
+	assert.True(t, r.ShouldSyncConfigMap(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "config"}}))
+	assert.False(t, r.ShouldSyncConfigMap(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "ca-bundle", Namespace: "other"}}))
+}
+
+func Test_ShouldSyncNamespace_ConfigMapSourceNamespaces(t *testing.T) {
+	r := configMapRule(typesv1.NamespaceRules{Exclude: []string{"excluded"}})
+
+	assert.False(t, r.ShouldSyncNamespace(namespace("source", nil)))
+	assert.False(t, r.ShouldSyncNamespace(namespace("config", nil)))
+	assert.False(t, r.ShouldSyncNamespace(namespace("excluded", nil)))
+	assert.True(t, r.ShouldSyncNamespace(namespace("a", nil)))
+}
@@ -0,0 +1,48 @@
+package v1
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/alehechka/kube-secret-sync/constants"
+	v1 "k8s.io/api/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+// AcceptsRule determines whether or not the annotations of the given target Namespace accept copies
+// from the rule with the given key and namespace rules.
+//
+// Annotations are evaluated in the following order:
+//  1. If the refuse annotation is true, no rule is accepted.
+//  2. If the accept-rules annotation is set, only the rules it lists are accepted.
+//  3. If the require-opt-in annotation is true, only rules including the namespace by its name are accepted.
+//
+// Matching IncludeRegex or NamespaceSelector does not opt a namespace in, since those may match namespaces
+// that the author of the rule never had in mind.
+func AcceptsRule(namespace *v1.Namespace, key string, rules NamespaceRules) bool {
+	if refuse, _ := strconv.ParseBool(namespace.Annotations[constants.RefuseSyncAnnotationKey]); refuse {
+		return false
+	}
+
+	if accepted, ok := namespace.Annotations[constants.AcceptRulesAnnotationKey]; ok {
+		for _, name := range strings.Split(accepted, ",") {
+			if strings.TrimSpace(name) == key {
+				return true
+			}
+		}
+		return false
+	}
+
+	if optIn, _ := strconv.ParseBool(namespace.Annotations[constants.RequireOptInAnnotationKey]); optIn {
+		return rules.Include.IsIncluded(namespace.Name)
+	}
+
+	return true
+}
+
+// refusedNamespaces returns every cached namespace that the rule matches but whose annotations refuse it.
+func refusedNamespaces(lister corelisters.NamespaceLister, matches func(namespace *v1.Namespace) bool, key string, rules NamespaceRules) []*v1.Namespace {
+	return filterNamespaces(lister, func(namespace *v1.Namespace) bool {
+		return matches(namespace) && !AcceptsRule(namespace, key, rules)
+	})
+}
@@ -0,0 +1,46 @@
+package v1_test
+
+import (
+	"testing"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/constants"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func annotatedNamespace(name string, annotations map[string]string) *v1.Namespace {
+	return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}}
+}
+
+func Test_AcceptsRule(t *testing.T) {
+	rules := typesv1.NamespaceRules{}
+	assert.True(t, typesv1.AcceptsRule(annotatedNamespace("a", nil), "rule", rules))
+
+	refusing := annotatedNamespace("a", map[string]string{
+		constants.RefuseSyncAnnotationKey:  "true",
+		constants.AcceptRulesAnnotationKey: "rule",
+	})
+	assert.False(t, typesv1.AcceptsRule(refusing, "rule", rules))
+
+	accepting := annotatedNamespace("a", map[string]string{constants.AcceptRulesAnnotationKey: "other, team-a/rule"})
+	assert.False(t, typesv1.AcceptsRule(accepting, "rule", rules))
+	assert.True(t, typesv1.AcceptsRule(accepting, "team-a/rule", rules))
+
+	optIn := annotatedNamespace("a", map[string]string{constants.RequireOptInAnnotationKey: "true"})
+	assert.False(t, typesv1.AcceptsRule(optIn, "rule", typesv1.NamespaceRules{IncludeRegex: []string{"a"}}))
+	assert.False(t, typesv1.AcceptsRule(optIn, "rule", typesv1.NamespaceRules{NamespaceSelector: &metav1.LabelSelector{}}))
+	assert.True(t, typesv1.AcceptsRule(optIn, "rule", typesv1.NamespaceRules{Include: []string{"a"}}))
+}
+
+func Test_ShouldSyncNamespace_Refused(t *testing.T) {
+	r := rule(typesv1.NamespaceRules{})
+	r.Name = "rule"
+
+	refusing := annotatedNamespace("a", map[string]string{constants.RefuseSyncAnnotationKey: "true"})
+	assert.False(t, r.ShouldSyncNamespace(refusing))
+	assert.False(t, r.ShouldSyncRemoteNamespace(refusing))
+	assert.True(t, r.IsRefusedByRemoteNamespace(refusing))
+	assert.False(t, r.IsRefusedByRemoteNamespace(annotatedNamespace("a", nil)))
+}
@@ -0,0 +1,129 @@
+package v1
+
+import (
+	"fmt"
+
+	"github.com/alehechka/kube-secret-sync/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// NamespacedSecretSyncRule is the definition for the NamespacedSecretSyncRule CRD.
+//
+// It lives in the namespace of its source Secrets and can only reference Secrets in that namespace.
+// Its target namespaces are further restricted to the namespaces allowed by the SecretSyncPolicies that apply to it.
+type NamespacedSecretSyncRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SecretSyncRuleSpec   `json:"spec"`
+	Status SecretSyncRuleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NamespacedSecretSyncRuleList is the definition for the NamespacedSecretSyncRule CRD list
+type NamespacedSecretSyncRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []NamespacedSecretSyncRule `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+
+// SecretSyncPolicy is the definition for the SecretSyncPolicy CRD
+type SecretSyncPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SecretSyncPolicySpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// SecretSyncPolicyList is the definition for the SecretSyncPolicy CRD list
+type SecretSyncPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []SecretSyncPolicy `json:"items"`
+}
+
+// +kubebuilder:object:generate=true
+
+// SecretSyncPolicySpec allows the NamespacedSecretSyncRules of the Sources namespaces to sync to the Targets namespaces.
+//
+// Without any SecretSyncPolicy, NamespacedSecretSyncRules do not sync to any namespace.
+type SecretSyncPolicySpec struct {
+	Sources NamespaceRules `json:"sources"`
+	Targets NamespaceRules `json:"targets"`
+}
+
+// SecretSyncRule converts the NamespacedSecretSyncRule into the SecretSyncRule that is synced on its behalf.
+//
+// Source Secrets without a namespace default to the namespace of the rule, and referencing a Secret in any other namespace is an error.
+// External sources and remote clusters are read with the permissions of the controller and are therefore not allowed.
+// The target namespaces of the converted rule are restricted to the Targets of every given policy whose Sources match the given Namespace of the rule.
+func (rule *NamespacedSecretSyncRule) SecretSyncRule(namespace *v1.Namespace, policies []*SecretSyncPolicy) (*SecretSyncRule, error) {
+	spec := rule.Spec.DeepCopy()
+
+	if len(spec.External) > 0 {
+		return nil, constants.ErrNamespacedExternalSource
+	}
+
+	if len(spec.Clusters) > 0 {
+		return nil, constants.ErrNamespacedRemoteCluster
+	}
+
+	defaultNamespace := func(reference *string) error {
+		if *reference == "" {
+			*reference = rule.Namespace
+		}
+		if *reference != rule.Namespace {
+			return fmt.Errorf("%w: %s", constants.ErrCrossNamespaceSource, *reference)
+		}
+		return nil
+	}
+
+	references := []*string{}
+	if spec.Secret.Name != "" {
+		references = append(references, &spec.Secret.Namespace)
+	}
+	for i := range spec.Secrets {
+		references = append(references, &spec.Secrets[i].Namespace)
+	}
+	if spec.Merge != nil {
+		for i := range spec.Merge.Sources {
+			references = append(references, &spec.Merge.Sources[i].Namespace)
+		}
+	}
+	if spec.SecretSelector != nil {
+		references = append(references, &spec.SecretSelector.Namespace)
+	}
+
+	for _, reference := range references {
+		if err := defaultNamespace(reference); err != nil {
+			return nil, err
+		}
+	}
+
+	converted := &SecretSyncRule{
+		TypeMeta:       rule.TypeMeta,
+		ObjectMeta:     *rule.ObjectMeta.DeepCopy(),
+		Spec:           *spec,
+		Status:         *rule.Status.DeepCopy(),
+		TargetPolicies: []NamespaceRules{},
+	}
+
+	for _, policy := range policies {
+		if policy.Spec.Sources.Matches(namespace) {
+			converted.TargetPolicies = append(converted.TargetPolicies, *policy.Spec.Targets.DeepCopy())
+		}
+	}
+
+	return converted, nil
+}
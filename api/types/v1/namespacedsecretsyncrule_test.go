@@ -0,0 +1,70 @@
+package v1_test
+
+import (
+	"testing"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/constants"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func namespacedRule(spec typesv1.SecretSyncRuleSpec) *typesv1.NamespacedSecretSyncRule {
+	return &typesv1.NamespacedSecretSyncRule{ObjectMeta: metav1.ObjectMeta{Name: "rule", Namespace: "team-a"}, Spec: spec}
+}
+
+func policy(sources, targets typesv1.NamespaceRules) *typesv1.SecretSyncPolicy {
+	return &typesv1.SecretSyncPolicy{Spec: typesv1.SecretSyncPolicySpec{Sources: sources, Targets: targets}}
+}
+
+func Test_NamespacedSecretSyncRule_DefaultsSourceNamespace(t *testing.T) {
+	r := namespacedRule(typesv1.SecretSyncRuleSpec{
+		Secret:         typesv1.Secret{Name: "a"},
+		Secrets:        []typesv1.Secret{{Name: "b"}},
+		SecretSelector: &typesv1.SecretSelector{},
+	})
+
+	converted, err := r.SecretSyncRule(namespace("team-a", nil), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "team-a", converted.Spec.Secret.Namespace)
+	assert.Equal(t, "team-a", converted.Spec.Secrets[0].Namespace)
+	assert.Equal(t, "team-a", converted.Spec.SecretSelector.Namespace)
+	assert.Empty(t, r.Spec.Secret.Namespace)
+}
+
+func Test_NamespacedSecretSyncRule_CrossNamespaceSource(t *testing.T) {
+	r := namespacedRule(typesv1.SecretSyncRuleSpec{Secrets: []typesv1.Secret{{Name: "b", Namespace: "team-b"}}})
+
+	_, err := r.SecretSyncRule(namespace("team-a", nil), nil)
+	assert.ErrorIs(t, err, constants.ErrCrossNamespaceSource)
+
+	r = namespacedRule(typesv1.SecretSyncRuleSpec{External: []typesv1.ExternalSource{{Name: "a", Provider: "file", Path: "a.env"}}})
+
+	_, err = r.SecretSyncRule(namespace("team-a", nil), nil)
+	assert.ErrorIs(t, err, constants.ErrNamespacedExternalSource)
+
+	for _, kubeconfigNamespace := range []string{"", "team-a", "team-b"} {
+		r = namespacedRule(typesv1.SecretSyncRuleSpec{Clusters: []typesv1.Cluster{{Name: "spoke", Secret: typesv1.Secret{Name: "kubeconfig", Namespace: kubeconfigNamespace}}}})
+
+		_, err = r.SecretSyncRule(namespace("team-a", nil), nil)
+		assert.ErrorIs(t, err, constants.ErrNamespacedRemoteCluster)
+	}
+}
+
+func Test_NamespacedSecretSyncRule_Policies(t *testing.T) {
+	r := namespacedRule(typesv1.SecretSyncRuleSpec{Secret: typesv1.Secret{Name: "a"}})
+	policies := []*typesv1.SecretSyncPolicy{
+		policy(typesv1.NamespaceRules{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}}}, typesv1.NamespaceRules{IncludeRegex: []string{"team-a-.*"}}),
+		policy(typesv1.NamespaceRules{Include: []string{"team-b"}}, typesv1.NamespaceRules{}),
+	}
+
+	converted, err := r.SecretSyncRule(namespace("team-a", map[string]string{"tenant": "true"}), policies)
+	assert.NoError(t, err)
+	assert.True(t, converted.ShouldSyncNamespace(namespace("team-a-dev", nil)))
+	assert.False(t, converted.ShouldSyncNamespace(namespace("team-b-dev", nil)))
+	assert.False(t, converted.ShouldSyncNamespace(namespace("team-a", nil)))
+
+	converted, err = r.SecretSyncRule(namespace("team-a", nil), policies)
+	assert.NoError(t, err)
+	assert.False(t, converted.ShouldSyncNamespace(namespace("team-a-dev", nil)))
+}
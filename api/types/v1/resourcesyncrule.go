@@ -0,0 +1,94 @@
+package v1
+
+import (
+	"github.com/alehechka/kube-secret-sync/api/types"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// ResourceSyncRule is the definition for the ResourceSyncRule CRD
+type ResourceSyncRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ResourceSyncRuleSpec `json:"spec"`
+	Status SecretSyncRuleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ResourceSyncRuleList is the definition for the ResourceSyncRule CRD list
+type ResourceSyncRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ResourceSyncRule `json:"items"`
+}
+
+// +kubebuilder:object:generate=true
+
+// ResourceSyncRuleSpec is the spec attribute of the ResourceSyncRule CRD
+type ResourceSyncRuleSpec struct {
+	Resource Resource      `json:"resource"`
+	Rules    ResourceRules `json:"rules"`
+}
+
+// +kubebuilder:object:generate=true
+
+// ResourceRules contains all rules for the resource to follow
+//
+// Key, label, annotation and conflict rules only apply to Secrets and are therefore not part of ResourceRules.
+type ResourceRules struct {
+	Namespaces NamespaceRules `json:"namespaces"`
+	Force      bool           `json:"force"`
+}
+
+// ShouldSyncNamespace determines whether or not the given Namespace should be synced to from the given source namespaces
+func (rules *ResourceRules) ShouldSyncNamespace(namespace *v1.Namespace, sourceNamespaces types.StringSlice) bool {
+	return shouldSyncNamespace(&rules.Namespaces, namespace, sourceNamespaces)
+}
+
+// +kubebuilder:object:generate=true
+
+// Resource defines the attributes of the namespaced object to sync
+type Resource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+}
+
+// GroupVersionKind returns the group, version and kind of the Resource
+func (resource *Resource) GroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(resource.APIVersion, resource.Kind)
+}
+
+// ShouldSyncResource determines whether or not the object with the given kind, namespace and name should be synced
+func (rule *ResourceSyncRule) ShouldSyncResource(gvk schema.GroupVersionKind, namespace, name string) bool {
+	resource := rule.Spec.Resource
+	return resource.GroupVersionKind() == gvk && resource.Namespace == namespace && resource.Name == name
+}
+
+// ShouldSyncNamespace determines whether or not the given Namespace should be synced
+func (rule *ResourceSyncRule) ShouldSyncNamespace(namespace *v1.Namespace) bool {
+	return rule.matchesNamespace(namespace) && AcceptsRule(namespace, rule.Name, rule.Spec.Rules.Namespaces)
+}
+
+func (rule *ResourceSyncRule) matchesNamespace(namespace *v1.Namespace) bool {
+	return rule.Spec.Rules.ShouldSyncNamespace(namespace, []string{rule.Spec.Resource.Namespace})
+}
+
+// Namespaces returns a list of all cached namespaces that the given Rule allows for syncing
+func (rule *ResourceSyncRule) Namespaces(lister corelisters.NamespaceLister) []*v1.Namespace {
+	return filterNamespaces(lister, rule.ShouldSyncNamespace)
+}
+
+// RefusedNamespaces returns a list of all cached namespaces that the given Rule matches but whose annotations refuse it
+func (rule *ResourceSyncRule) RefusedNamespaces(lister corelisters.NamespaceLister) []*v1.Namespace {
+	return refusedNamespaces(lister, rule.matchesNamespace, rule.Name, rule.Spec.Rules.Namespaces)
+}
@@ -0,0 +1,34 @@
+package v1_test
+
+import (
+	"testing"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func resourceRule(namespaces typesv1.NamespaceRules) *typesv1.ResourceSyncRule {
+	return &typesv1.ResourceSyncRule{Spec: typesv1.ResourceSyncRuleSpec{
+		Resource: typesv1.Resource{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy", Name: "deny-all", Namespace: "source"},
+		Rules:    typesv1.ResourceRules{Namespaces: namespaces},
+	}}
+}
+
+func Test_ShouldSyncResource(t *testing.T) {
+	r := resourceRule(typesv1.NamespaceRules{})
+	gvk := schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"}
+
+	assert.True(t, r.ShouldSyncResource(gvk, "source", "deny-all"))
+	assert.False(t, r.ShouldSyncResource(gvk, "other", "deny-all"))
+	assert.False(t, r.ShouldSyncResource(gvk, "source", "allow-all"))
+	assert.False(t, r.ShouldSyncResource(schema.GroupVersionKind{Version: "v1", Kind: "LimitRange"}, "source", "deny-all"))
+}
+
+func Test_ShouldSyncNamespace_ResourceSourceNamespace(t *testing.T) {
+	r := resourceRule(typesv1.NamespaceRules{Exclude: []string{"excluded"}})
+
+	assert.False(t, r.ShouldSyncNamespace(namespace("source", nil)))
+	assert.False(t, r.ShouldSyncNamespace(namespace("excluded", nil)))
+	assert.True(t, r.ShouldSyncNamespace(namespace("a", nil)))
+}
@@ -1,22 +1,32 @@
 package v1
 
 import (
-	"context"
+	"path"
+	"strings"
 
 	"github.com/alehechka/kube-secret-sync/api/types"
+	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corelisters "k8s.io/client-go/listers/core/v1"
 )
 
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
 
 // SecretSyncRule is the definition for the SecretSyncRule CRD
 type SecretSyncRule struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec SecretSyncRuleSpec `json:"spec"`
+	Spec   SecretSyncRuleSpec   `json:"spec"`
+	Status SecretSyncRuleStatus `json:"status,omitempty"`
+
+	// TargetPolicies restrict the target namespaces of a rule converted from a NamespacedSecretSyncRule
+	// to the namespaces allowed by any of them. They are resolved by the controller and never serialized.
+	TargetPolicies []NamespaceRules `json:"-"`
 }
 
 // +kubebuilder:object:root=true
@@ -32,9 +42,22 @@ type SecretSyncRuleList struct {
 // +kubebuilder:object:generate=true
 
 // SecretSyncRuleSpec is the spec attribute of the SecretSyncRule CRD
+//
+// The Secrets to sync are the union of Secret, every reference in Secrets, every source of Merge, every Secret matched by SecretSelector
+// and every External source.
+// If Merge is set, they are combined into a single Secret before syncing.
+// Besides the local cluster, the Secrets are synced to the namespaces of every remote cluster in Clusters that match Rules.
 type SecretSyncRuleSpec struct {
-	Secret Secret `json:"secret"`
-	Rules  Rules  `json:"rules"`
+	Secret         Secret           `json:"secret,omitempty"`
+	Secrets        []Secret         `json:"secrets,omitempty"`
+	SecretSelector *SecretSelector  `json:"secretSelector,omitempty"`
+	Merge          *Merge           `json:"merge,omitempty"`
+	External       []ExternalSource `json:"external,omitempty"`
+	Target         *Target          `json:"target,omitempty"`
+	Template       *SecretTemplate  `json:"template,omitempty"`
+	Clusters       []Cluster        `json:"clusters,omitempty"`
+	DeletionPolicy *DeletionPolicy  `json:"deletionPolicy,omitempty"`
+	Rules          Rules            `json:"rules"`
 }
 
 // +kubebuilder:object:generate=true
@@ -47,59 +70,450 @@ type Secret struct {
 
 // +kubebuilder:object:generate=true
 
+// ExternalSource defines a Secret to sync whose data is read from a source provider instead of the cluster.
+// The data is synced as if it were a Secret with the given Name, so it can be merged, filtered and templated like any other source.
+type ExternalSource struct {
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+	Path     string `json:"path"`
+}
+
+// Identifier returns the provider-qualified identifier of the ExternalSource, which stands in for the namespace of its Secret
+func (source *ExternalSource) Identifier() string {
+	return source.Provider + ":" + source.Path
+}
+
+// Matches determines whether or not the data of the ExternalSource may have changed after the given path of the given provider changed.
+// Paths are relative to the root of the provider, so a source reading a directory matches every path below it.
+func (source *ExternalSource) Matches(provider, changed string) bool {
+	if source.Provider != provider {
+		return false
+	}
+
+	sourcePath := strings.TrimPrefix(path.Clean("/"+source.Path), "/")
+	changed = strings.TrimPrefix(path.Clean("/"+changed), "/")
+
+	return sourcePath == "" || changed == sourcePath || strings.HasPrefix(changed, sourcePath+"/")
+}
+
+// DeletionPolicyType defines what happens to a synced copy that is no longer desired
+type DeletionPolicyType string
+
+const (
+	// DeletionPolicyDelete deletes the synced copy.
+	DeletionPolicyDelete DeletionPolicyType = "Delete"
+	// DeletionPolicyOrphan keeps the synced copy but removes its managed-by annotation, rule labels and owner references,
+	// so that it is no longer controlled by kube-secret-sync.
+	DeletionPolicyOrphan DeletionPolicyType = "Orphan"
+	// DeletionPolicyRetain keeps the synced copy as it is, so that a rule syncing it again picks it back up.
+	DeletionPolicyRetain DeletionPolicyType = "Retain"
+)
+
+// +kubebuilder:object:generate=true
+
+// DeletionPolicy defines what happens to the synced copies of a rule when the rule is deleted and when their source Secret disappears.
+// Both default to Delete.
+type DeletionPolicy struct {
+	OnRuleDeletion   DeletionPolicyType `json:"onRuleDeletion,omitempty"`
+	OnSourceDeletion DeletionPolicyType `json:"onSourceDeletion,omitempty"`
+}
+
+// RuleDeletionPolicy returns what happens to the synced copies when the rule is deleted
+func (spec *SecretSyncRuleSpec) RuleDeletionPolicy() DeletionPolicyType {
+	if spec.DeletionPolicy == nil {
+		return DeletionPolicyDelete
+	}
+
+	return spec.DeletionPolicy.OnRuleDeletion.OrDelete()
+}
+
+// SourceDeletionPolicy returns what happens to the synced copies of a source Secret that disappeared
+func (spec *SecretSyncRuleSpec) SourceDeletionPolicy() DeletionPolicyType {
+	if spec.DeletionPolicy == nil {
+		return DeletionPolicyDelete
+	}
+
+	return spec.DeletionPolicy.OnSourceDeletion.OrDelete()
+}
+
+// OrDelete returns the DeletionPolicyType, defaulting unset and unknown policies to DeletionPolicyDelete
+func (policy DeletionPolicyType) OrDelete() DeletionPolicyType {
+	switch policy {
+	case DeletionPolicyOrphan, DeletionPolicyRetain:
+		return policy
+	}
+
+	return DeletionPolicyDelete
+}
+
+// DefaultKubeconfigKey is the data key read from a kubeconfig Secret when a Cluster does not set one.
+const DefaultKubeconfigKey = "kubeconfig"
+
+// +kubebuilder:object:generate=true
+
+// Cluster references a remote cluster to sync to by the Secret holding its kubeconfig
+type Cluster struct {
+	Name   string `json:"name"`
+	Secret Secret `json:"secret"`
+	Key    string `json:"key,omitempty"`
+}
+
+// KubeconfigKey returns the data key of the kubeconfig in the Secret of the Cluster
+func (cluster *Cluster) KubeconfigKey() string {
+	if cluster.Key == "" {
+		return DefaultKubeconfigKey
+	}
+
+	return cluster.Key
+}
+
+// +kubebuilder:object:generate=true
+
+// SecretSelector selects the Secrets to sync from a source namespace by their labels
+type SecretSelector struct {
+	Namespace            string `json:"namespace"`
+	metav1.LabelSelector `json:",inline"`
+}
+
+// +kubebuilder:object:generate=true
+
+// Merge combines every source Secret of a rule into a single Secret.
+//
+// Sources are merged in the order Secret, Secrets, Sources, the Secrets matched by SecretSelector sorted by name and then External.
+// When several sources define the same key, the source merged last wins.
+type Merge struct {
+	Name    string        `json:"name"`
+	Sources []MergeSource `json:"sources,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// MergeSource defines a Secret to merge and the prefix to add to its keys
+type MergeSource struct {
+	Secret `json:",inline"`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
 // Rules contains all rules for the secret to follow
+//
+// ConflictPolicy supersedes Force, which is kept for rules that predate it.
 type Rules struct {
-	Namespaces NamespaceRules `json:"namespaces"`
-	Force      bool           `json:"force"`
+	Namespaces     NamespaceRules `json:"namespaces"`
+	Keys           KeyRules       `json:"keys,omitempty"`
+	Labels         MetadataRules  `json:"labels,omitempty"`
+	Annotations    MetadataRules  `json:"annotations,omitempty"`
+	Force          bool           `json:"force"`
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+}
+
+// ConflictPolicy defines how a rule handles an existing Secret in a target namespace that it does not manage
+type ConflictPolicy string
+
+const (
+	// ConflictPolicySkip leaves the existing Secret untouched.
+	ConflictPolicySkip ConflictPolicy = "Skip"
+	// ConflictPolicyOverwrite replaces the existing Secret with the synced copy, and deletes it when it is no longer desired.
+	ConflictPolicyOverwrite ConflictPolicy = "Overwrite"
+	// ConflictPolicyAdopt takes ownership of the existing Secret only if its content already matches the synced copy.
+	ConflictPolicyAdopt ConflictPolicy = "Adopt"
+	// ConflictPolicyMerge adds the keys missing from the existing Secret and keeps the existing ones, leaving it unmanaged.
+	ConflictPolicyMerge ConflictPolicy = "Merge"
+	// ConflictPolicyFail leaves the existing Secret untouched and reports the conflict on the rule.
+	ConflictPolicyFail ConflictPolicy = "Fail"
+)
+
+// OnConflict returns the conflict policy of the rules.
+// Without a valid ConflictPolicy, it is Overwrite if Force is set and Skip otherwise, which is how rules that predate conflict policies behave.
+func (rules *Rules) OnConflict() ConflictPolicy {
+	switch rules.ConflictPolicy {
+	case ConflictPolicySkip, ConflictPolicyOverwrite, ConflictPolicyAdopt, ConflictPolicyMerge, ConflictPolicyFail:
+		return rules.ConflictPolicy
+	}
+
+	if rules.Force {
+		return ConflictPolicyOverwrite
+	}
+	return ConflictPolicySkip
+}
+
+// +kubebuilder:object:generate=true
+
+// KeyRules include all rules for the data keys to sync.
+//
+// Include and Exclude accept exact keys and glob patterns, and Exclude takes precedence over Include.
+// Rename maps source keys to the keys written to the synced copies, a renamed key replaces a synced key with the same name.
+type KeyRules struct {
+	Include types.StringSlice `json:"include,omitempty"`
+	Exclude types.StringSlice `json:"exclude,omitempty"`
+	Rename  map[string]string `json:"rename,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// MetadataRules include all rules for the labels or annotations copied from the source Secrets to the synced copies.
+//
+// Include and Exclude accept exact keys, glob patterns and prefixes such as example.com/*, and Exclude takes precedence over Include.
+// Extra adds labels or annotations to every synced copy, replacing copied ones with the same key.
+// Labels and annotations set by kube-secret-sync itself are neither filtered nor replaced.
+type MetadataRules struct {
+	Include types.StringSlice `json:"include,omitempty"`
+	Exclude types.StringSlice `json:"exclude,omitempty"`
+	Extra   map[string]string `json:"extra,omitempty"`
 }
 
 // +kubebuilder:object:generate=true
 
 // NamespaceRules include all rules for namepsaces to sync to.
+//
+// Rules are evaluated in the following order:
+//  1. The namespaces of the source Secrets and namespaces that are terminating are never synced to.
+//  2. Namespaces matching Exclude or ExcludeRegex are not synced to.
+//  3. If NamespaceSelector is set, namespaces whose labels do not match it are not synced to.
+//  4. If Include and IncludeRegex are both empty, all remaining namespaces are synced to,
+//     otherwise only namespaces matching Include or IncludeRegex are synced to.
 type NamespaceRules struct {
-	Exclude      types.StringSlice `json:"exclude"`
-	ExcludeRegex types.StringSlice `json:"excludeRegex"`
-	Include      types.StringSlice `json:"include"`
-	IncludeRegex types.StringSlice `json:"includeRegex"`
+	Exclude           types.StringSlice     `json:"exclude"`
+	ExcludeRegex      types.StringSlice     `json:"excludeRegex"`
+	Include           types.StringSlice     `json:"include"`
+	IncludeRegex      types.StringSlice     `json:"includeRegex"`
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// SecretReferences returns every Secret explicitly referenced by the spec
+func (spec *SecretSyncRuleSpec) SecretReferences() (references []Secret) {
+	if spec.Secret.Name != "" {
+		references = append(references, spec.Secret)
+	}
+
+	references = append(references, spec.Secrets...)
+
+	if spec.Merge != nil {
+		for _, source := range spec.Merge.Sources {
+			references = append(references, source.Secret)
+		}
+	}
+
+	return references
+}
+
+// KeyPrefix returns the prefix to add to the keys of the given source Secret when merging it
+func (merge *Merge) KeyPrefix(secret *v1.Secret) string {
+	for _, source := range merge.Sources {
+		if source.Name == secret.Name && source.Namespace == secret.Namespace {
+			return source.Prefix
+		}
+	}
+
+	return ""
+}
+
+// SourceNamespaces returns every namespace that the spec syncs Secrets from
+func (spec *SecretSyncRuleSpec) SourceNamespaces() types.StringSlice {
+	namespaces := sets.NewString()
+
+	for _, reference := range spec.SecretReferences() {
+		namespaces.Insert(reference.Namespace)
+	}
+
+	if spec.SecretSelector != nil {
+		namespaces.Insert(spec.SecretSelector.Namespace)
+	}
+
+	return namespaces.List()
+}
+
+// Selector converts the SecretSelector into a labels.Selector.
+// An invalid SecretSelector is logged and matches no Secrets.
+func (selector *SecretSelector) Selector() labels.Selector {
+	converted, err := metav1.LabelSelectorAsSelector(&selector.LabelSelector)
+	if err != nil {
+		log.WithFields(log.Fields{"secretSelector": selector.LabelSelector.String()}).Errorf(err.Error())
+		return labels.Nothing()
+	}
+
+	return converted
+}
+
+// Matches determines whether or not the given Secret is selected by the SecretSelector
+func (selector *SecretSelector) Matches(secret *v1.Secret) bool {
+	return selector.Namespace == secret.Namespace && selector.Selector().Matches(labels.Set(secret.Labels))
 }
 
 // ShouldSyncSecret determines whether or not the given Secret should be synced
 func (rule *SecretSyncRule) ShouldSyncSecret(secret *v1.Secret) bool {
-	return rule.Spec.Secret.Name == secret.Name && rule.Spec.Secret.Namespace == secret.Namespace
+	for _, reference := range rule.Spec.SecretReferences() {
+		if reference.Name == secret.Name && reference.Namespace == secret.Namespace {
+			return true
+		}
+	}
+
+	return rule.Spec.SecretSelector != nil && rule.Spec.SecretSelector.Matches(secret)
 }
 
 // ShouldSyncNamespace determines whether or not the given Namespace should be synced
 func (rule *SecretSyncRule) ShouldSyncNamespace(namespace *v1.Namespace) bool {
-	rules := rule.Spec.Rules
+	return rule.matchesNamespace(namespace) && AcceptsRule(namespace, rule.key(), rule.Spec.Rules.Namespaces)
+}
+
+// ShouldSyncRemoteNamespace determines whether or not the given Namespace of a remote cluster should be synced.
+// Unlike ShouldSyncNamespace, namespaces sharing their name with a source namespace are not skipped, since they are a different namespace.
+func (rule *SecretSyncRule) ShouldSyncRemoteNamespace(namespace *v1.Namespace) bool {
+	return rule.matchesRemoteNamespace(namespace) && AcceptsRule(namespace, rule.key(), rule.Spec.Rules.Namespaces)
+}
+
+// IsRefusedByRemoteNamespace determines whether or not the given Namespace of a remote cluster is matched by the rule but refuses it.
+func (rule *SecretSyncRule) IsRefusedByRemoteNamespace(namespace *v1.Namespace) bool {
+	return rule.matchesRemoteNamespace(namespace) && !AcceptsRule(namespace, rule.key(), rule.Spec.Rules.Namespaces)
+}
+
+func (rule *SecretSyncRule) matchesNamespace(namespace *v1.Namespace) bool {
+	return rule.Spec.Rules.ShouldSyncNamespace(namespace, rule.Spec.SourceNamespaces()) && rule.AllowedByPolicies(namespace)
+}
+
+func (rule *SecretSyncRule) matchesRemoteNamespace(namespace *v1.Namespace) bool {
+	return rule.Spec.Rules.ShouldSyncNamespace(namespace, nil) && rule.AllowedByPolicies(namespace)
+}
+
+// key returns the name that target namespaces accept the rule by, prefixed with its namespace for namespaced rules.
+func (rule *SecretSyncRule) key() string {
+	if rule.Namespace != "" {
+		return rule.Namespace + "/" + rule.Name
+	}
+
+	return rule.Name
+}
+
+// UsesKubeconfig determines whether or not the given Secret holds the kubeconfig of any Cluster of the rule
+func (rule *SecretSyncRule) UsesKubeconfig(secret *v1.Secret) bool {
+	for _, cluster := range rule.Spec.Clusters {
+		if cluster.Secret.Name == secret.Name && cluster.Secret.Namespace == secret.Namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UsesExternalSource determines whether or not any External source of the rule reads the given changed path of the given provider
+func (rule *SecretSyncRule) UsesExternalSource(provider, changed string) bool {
+	for i := range rule.Spec.External {
+		if rule.Spec.External[i].Matches(provider, changed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowedByPolicies determines whether or not the TargetPolicies allow syncing to the given Namespace.
+// SecretSyncRules are cluster scoped and therefore not restricted by any policy.
+func (rule *SecretSyncRule) AllowedByPolicies(namespace *v1.Namespace) bool {
+	if rule.Namespace == "" {
+		return true
+	}
 
-	if rule.Spec.Secret.Namespace == namespace.Name {
+	for i := range rule.TargetPolicies {
+		if rule.TargetPolicies[i].Matches(namespace) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ShouldSyncNamespace determines whether or not the given Namespace should be synced to from the given source namespaces
+func (rules *Rules) ShouldSyncNamespace(namespace *v1.Namespace, sourceNamespaces types.StringSlice) bool {
+	return shouldSyncNamespace(&rules.Namespaces, namespace, sourceNamespaces)
+}
+
+func shouldSyncNamespace(rules *NamespaceRules, namespace *v1.Namespace, sourceNamespaces types.StringSlice) bool {
+	if sourceNamespaces.IsIncluded(namespace.Name) || namespace.Status.Phase == v1.NamespaceTerminating {
+		return false
+	}
+
+	return rules.Matches(namespace)
+}
+
+// Matches determines whether or not the given Namespace is matched by the exclude, selector and include rules
+func (rules *NamespaceRules) Matches(namespace *v1.Namespace) bool {
+	if rules.Exclude.IsExcluded(namespace.Name) || rules.ExcludeRegex.IsRegexExcluded(namespace.Name) {
 		return false
 	}
 
-	if rules.Namespaces.Exclude.IsExcluded(namespace.Name) || rules.Namespaces.ExcludeRegex.IsRegexExcluded(namespace.Name) {
+	if !rules.MatchesSelector(namespace) {
 		return false
 	}
 
-	if rules.Namespaces.Include.IsEmpty() && rules.Namespaces.IncludeRegex.IsEmpty() {
+	if rules.Include.IsEmpty() && rules.IncludeRegex.IsEmpty() {
+		return true
+	}
+
+	return rules.Include.IsIncluded(namespace.Name) || rules.IncludeRegex.IsRegexIncluded(namespace.Name)
+}
+
+// MatchesSelector determines whether or not the labels of the given Namespace match the NamespaceSelector.
+// If no NamespaceSelector is set, all namespaces match. An invalid NamespaceSelector matches no namespaces.
+func (rules *NamespaceRules) MatchesSelector(namespace *v1.Namespace) bool {
+	if rules.NamespaceSelector == nil {
 		return true
 	}
 
-	if rules.Namespaces.Include.IsIncluded(namespace.Name) || rules.Namespaces.IncludeRegex.IsRegexIncluded(namespace.Name) {
+	selector, err := metav1.LabelSelectorAsSelector(rules.NamespaceSelector)
+	if err != nil {
+		log.WithFields(log.Fields{"namespaceSelector": rules.NamespaceSelector.String()}).Errorf(err.Error())
+		return false
+	}
+
+	return selector.Matches(labels.Set(namespace.Labels))
+}
+
+// ShouldSyncKey determines whether or not the given data key should be synced
+func (keys *KeyRules) ShouldSyncKey(key string) bool {
+	return !keys.Exclude.IsGlobExcluded(key) && keys.Include.IsGlobIncluded(key)
+}
+
+// ShouldCopyKey determines whether or not the label or annotation with the given key is copied from the source Secrets
+func (metadata *MetadataRules) ShouldCopyKey(key string) bool {
+	return !metadata.Exclude.IsPrefixExcluded(key) && metadata.Include.IsPrefixIncluded(key)
+}
+
+// Manages determines whether or not the label or annotation with the given key is copied or added by the rules
+func (metadata *MetadataRules) Manages(key string) bool {
+	if _, ok := metadata.Extra[key]; ok {
 		return true
 	}
+	return metadata.ShouldCopyKey(key)
+}
 
-	return false
+// TargetKey returns the key that the given data key is synced as
+func (keys *KeyRules) TargetKey(key string) string {
+	if renamed, ok := keys.Rename[key]; ok && renamed != "" {
+		return renamed
+	}
+
+	return key
+}
+
+// Namespaces returns a list of all cached namespaces that the given Rule allows for syncing
+func (rule *SecretSyncRule) Namespaces(lister corelisters.NamespaceLister) []*v1.Namespace {
+	return filterNamespaces(lister, rule.ShouldSyncNamespace)
+}
+
+// RefusedNamespaces returns a list of all cached namespaces that the given Rule matches but whose annotations refuse it
+func (rule *SecretSyncRule) RefusedNamespaces(lister corelisters.NamespaceLister) []*v1.Namespace {
+	return refusedNamespaces(lister, rule.matchesNamespace, rule.key(), rule.Spec.Rules.Namespaces)
 }
 
-// Namespaces returns a list of all namespaces that the given Rule allows for syncing
-func (rule *SecretSyncRule) Namespaces(ctx context.Context, clientset kubernetes.Interface) (namespaces []v1.Namespace) {
-	list, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+func filterNamespaces(lister corelisters.NamespaceLister, shouldSync func(namespace *v1.Namespace) bool) (namespaces []*v1.Namespace) {
+	list, err := lister.List(labels.Everything())
 	if err != nil {
 		return
 	}
 
-	for _, namespace := range list.Items {
-		if rule.ShouldSyncNamespace(&namespace) {
+	for _, namespace := range list {
+		if shouldSync(namespace) {
 			namespaces = append(namespaces, namespace)
 		}
 	}
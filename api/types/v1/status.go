@@ -0,0 +1,69 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionReady is True when the source Secret was found and synced to every targeted Namespace.
+	ConditionReady = "Ready"
+	// ConditionSourceFound is True when the source Secret of the rule exists.
+	ConditionSourceFound = "SourceFound"
+	// ConditionSynced is True when the source Secret was synced to every targeted Namespace.
+	ConditionSynced = "Synced"
+	// ConditionDegraded is True when syncing to at least one targeted Namespace failed.
+	ConditionDegraded = "Degraded"
+	// ConditionConflict is True when an existing copy that the rule does not manage blocks syncing to at least one targeted Namespace.
+	ConditionConflict = "Conflict"
+)
+
+// +kubebuilder:object:generate=true
+
+// SecretSyncRuleStatus is the status attribute of the SecretSyncRule CRD
+type SecretSyncRuleStatus struct {
+	ObservedGeneration int64               `json:"observedGeneration,omitempty"`
+	Conditions         []metav1.Condition  `json:"conditions,omitempty"`
+	Targets            int                 `json:"targets"`
+	Synced             int                 `json:"synced"`
+	SyncedNamespaces   []string            `json:"syncedNamespaces,omitempty"`
+	RefusedNamespaces  []string            `json:"refusedNamespaces,omitempty"`
+	Failures           []NamespaceFailure  `json:"failures,omitempty"`
+	Conflicts          []NamespaceConflict `json:"conflicts,omitempty"`
+	Clusters           []ClusterStatus     `json:"clusters,omitempty"`
+	LastSyncTime       *metav1.Time        `json:"lastSyncTime,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// ClusterStatus describes the outcome of the last sync to a remote cluster
+type ClusterStatus struct {
+	Name              string              `json:"name"`
+	Secret            *Secret             `json:"secret,omitempty"`
+	Key               string              `json:"key,omitempty"`
+	Connected         bool                `json:"connected"`
+	Message           string              `json:"message,omitempty"`
+	Targets           int                 `json:"targets"`
+	Synced            int                 `json:"synced"`
+	SyncedNamespaces  []string            `json:"syncedNamespaces,omitempty"`
+	RefusedNamespaces []string            `json:"refusedNamespaces,omitempty"`
+	Failures          []NamespaceFailure  `json:"failures,omitempty"`
+	Conflicts         []NamespaceConflict `json:"conflicts,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// NamespaceConflict describes how an existing Secret that the rule does not manage was handled during the last sync
+type NamespaceConflict struct {
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	Resolution string `json:"resolution"`
+}
+
+// +kubebuilder:object:generate=true
+
+// NamespaceFailure describes why the source Secret could not be synced to a Namespace
+type NamespaceFailure struct {
+	Namespace string `json:"namespace"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message,omitempty"`
+}
@@ -0,0 +1,37 @@
+package v1
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alehechka/kube-secret-sync/constants"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// +kubebuilder:object:generate=true
+
+// Target defines how the synced copies of a Secret are written to the target namespaces
+type Target struct {
+	// Name is a Go template for the name of the synced copy, rendered with TemplateData.
+	// If empty, the copy keeps the name of its source Secret.
+	Name string `json:"name,omitempty"`
+}
+
+// TargetName renders the name of the copy of the given source Secret in the given Namespace
+func (rule *SecretSyncRule) TargetName(secret *v1.Secret, namespace *v1.Namespace) (string, error) {
+	if rule.Spec.Target == nil || rule.Spec.Target.Name == "" {
+		return secret.Name, nil
+	}
+
+	name, err := renderTemplate("name", rule.Spec.Target.Name, NewTemplateData(secret, namespace))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", constants.ErrInvalidTargetName, err.Error())
+	}
+
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", fmt.Errorf("%w: %q: %s", constants.ErrInvalidTargetName, name, strings.Join(errs, ", "))
+	}
+
+	return name, nil
+}
@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/alehechka/kube-secret-sync/constants"
+	v1 "k8s.io/api/core/v1"
+)
+
+// +kubebuilder:object:generate=true
+
+// SecretTemplate defines data rendered into the synced copies for every target namespace
+type SecretTemplate struct {
+	// Data maps keys of the synced copies to Go templates rendered with TemplateData.
+	// Rendered keys replace synced keys with the same name.
+	Data map[string]string `json:"data,omitempty"`
+}
+
+// TemplateData is the data available to the templates of a SecretSyncRule
+type TemplateData struct {
+	// Source references the source Secret.
+	Source TemplateSource
+	// Data contains the data of the source Secret as strings.
+	Data map[string]string
+	// Namespace is the target Namespace.
+	Namespace *v1.Namespace
+}
+
+// TemplateSource references the source Secret in TemplateData
+type TemplateSource struct {
+	// Name is the name of the source Secret.
+	Name      string
+	namespace string
+}
+
+// Namespace returns the namespace of the source Secret, which is qualified by the provider for external sources.
+// Sources without a namespace, such as merged Secrets, fail to render rather than rendering an empty namespace.
+func (source TemplateSource) Namespace() (string, error) {
+	if source.namespace == "" {
+		return "", constants.ErrSourceNamespaceUnknown
+	}
+
+	return source.namespace, nil
+}
+
+// NewTemplateData builds the TemplateData for the copy of the given source Secret in the given Namespace
+func NewTemplateData(secret *v1.Secret, namespace *v1.Namespace) TemplateData {
+	data := make(map[string]string, len(secret.Data)+len(secret.StringData))
+	for key, value := range secret.Data {
+		data[key] = string(value)
+	}
+	for key, value := range secret.StringData {
+		data[key] = value
+	}
+
+	return TemplateData{
+		Source:    TemplateSource{Name: secret.Name, namespace: secret.Namespace},
+		Data:      data,
+		Namespace: namespace,
+	}
+}
+
+// IsTemplated determines whether or not the synced copies of the rule are rendered from templates
+func (rule *SecretSyncRule) IsTemplated() bool {
+	return (rule.Spec.Target != nil && rule.Spec.Target.Name != "") ||
+		(rule.Spec.Template != nil && len(rule.Spec.Template.Data) > 0)
+}
+
+// RenderTemplate renders the template data of the rule for the copy of the given source Secret in the given Namespace.
+// Nothing is returned unless every value renders, so a copy is never written with partially rendered data.
+func (rule *SecretSyncRule) RenderTemplate(secret *v1.Secret, namespace *v1.Namespace) (map[string][]byte, error) {
+	if rule.Spec.Template == nil || len(rule.Spec.Template.Data) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(rule.Spec.Template.Data))
+	for key := range rule.Spec.Template.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	data := NewTemplateData(secret, namespace)
+	rendered := make(map[string][]byte, len(keys))
+
+	for _, key := range keys {
+		value, err := renderTemplate(key, rule.Spec.Template.Data[key], data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", constants.ErrInvalidTemplate, err.Error())
+		}
+		rendered[key] = []byte(value)
+	}
+
+	return rendered, nil
+}
+
+func renderTemplate(name, text string, data TemplateData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", err
+	}
+
+	return rendered.String(), nil
+}
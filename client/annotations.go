@@ -3,25 +3,138 @@ package client
 import (
 	"reflect"
 
+	"github.com/alehechka/kube-secret-sync/api/types"
 	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
 	"github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
 	"github.com/alehechka/kube-secret-sync/constants"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/uuid"
 )
 
-func AnnotationsAreEqual(a, b map[string]string) bool {
-	aCopy := CopyAnnotations(a)
-	bCopy := CopyAnnotations(b)
+// AnnotationsAreEqual compares the given annotations, ignoring the ones marking a copy as managed by kube-secret-sync and recording its provenance.
+// If rules are given, annotations that the rules neither copy nor add are ignored as well.
+func AnnotationsAreEqual(a, b map[string]string, rules *typesv1.MetadataRules) bool {
+	aCopy := filterMetadata(CopyAnnotations(a), rules, constants.DeletionPolicyAnnotationKey)
+	bCopy := filterMetadata(CopyAnnotations(b), rules, constants.DeletionPolicyAnnotationKey)
 
 	return reflect.DeepEqual(aCopy, bCopy)
 }
 
+// LabelsAreEqual compares the given labels, ignoring the ones pointing a copy at its rule.
+// If rules are given, labels that the rules neither copy nor add are ignored as well.
+func LabelsAreEqual(a, b map[string]string, rules *typesv1.MetadataRules) bool {
+	aCopy := filterMetadata(CopyLabels(a), rules)
+	bCopy := filterMetadata(CopyLabels(b), rules)
+
+	return reflect.DeepEqual(aCopy, bCopy)
+}
+
+// filterMetadata drops the labels or annotations that the given rules neither copy nor add, except for the given keys.
+func filterMetadata(m map[string]string, rules *typesv1.MetadataRules, keep ...string) map[string]string {
+	if rules == nil {
+		return m
+	}
+
+	for key := range m {
+		if !rules.Manages(key) && !types.StringSlice(keep).IsIncluded(key) {
+			delete(m, key)
+		}
+	}
+
+	return m
+}
+
+// CopyMetadata returns the labels or annotations of the given map that the given rules copy, together with their extra ones.
+func CopyMetadata(m map[string]string, rules *typesv1.MetadataRules) map[string]string {
+	copy := make(map[string]string)
+
+	for key, value := range m {
+		if rules.ShouldCopyKey(key) {
+			copy[key] = value
+		}
+	}
+	for key, value := range rules.Extra {
+		copy[key] = value
+	}
+
+	return copy
+}
+
+func CopyLabels(m map[string]string) map[string]string {
+	copy := make(map[string]string)
+
+	for key, value := range m {
+		if key == constants.RuleLabelKey || key == constants.RuleNamespaceLabelKey || key == constants.RuleKindLabelKey {
+			continue
+		}
+		copy[key] = value
+	}
+
+	return copy
+}
+
+// LabelRule labels the given labels with the name, namespace and kind of the given rule.
+func LabelRule(m map[string]string, kind string, rule metav1.Object) map[string]string {
+	if m == nil {
+		m = make(map[string]string)
+	}
+	m[constants.RuleLabelKey] = rule.GetName()
+	m[constants.RuleKindLabelKey] = kind
+	if namespace := rule.GetNamespace(); namespace != "" {
+		m[constants.RuleNamespaceLabelKey] = namespace
+	}
+	return m
+}
+
+// SyncedByOtherRule determines whether or not the given managed object was synced by a rule other than the given SecretSyncRule,
+// going by its rule labels. Objects without a rule label predate NamespacedSecretSyncRules, so they can only belong to
+// a SecretSyncRule, and are attributed by their owner references if they have any.
+func SyncedByOtherRule(obj metav1.Object, rule *typesv1.SecretSyncRule) bool {
+	if syncedByRuleKind(obj, clientset.SecretSyncRule) != clientset.SecretSyncRule {
+		return true
+	}
+
+	name, ok := obj.GetLabels()[constants.RuleLabelKey]
+	if !ok {
+		if rule.Namespace != "" {
+			return true
+		}
+		for _, owner := range obj.GetOwnerReferences() {
+			if owner.Kind == clientset.SecretSyncRule && owner.Name != rule.Name {
+				return true
+			}
+		}
+		return false
+	}
+
+	return name != rule.Name || obj.GetLabels()[constants.RuleNamespaceLabelKey] != rule.Namespace
+}
+
+// ConfigMapSyncedByOtherRule determines whether or not the given managed object was synced by a rule other than the given
+// ConfigMapSyncRule, going by its rule labels and owner references.
+func ConfigMapSyncedByOtherRule(obj metav1.Object, rule *typesv1.ConfigMapSyncRule) bool {
+	if syncedByRuleKind(obj, clientset.ConfigMapSyncRule) != clientset.ConfigMapSyncRule {
+		return true
+	}
+
+	for _, name := range syncedByRuleNames(obj, clientset.ConfigMapSyncRule) {
+		if name != rule.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CopyAnnotations copies the given annotations, leaving out the ones Kubernetes and kube-secret-sync use for bookkeeping.
 func CopyAnnotations(m map[string]string) map[string]string {
 	copy := make(map[string]string)
 
 	for key, value := range m {
-		if key == constants.ManagedByAnnotationKey || key == constants.LastAppliedConfigurationAnnotationKey {
+		switch key {
+		case constants.ManagedByAnnotationKey, constants.LastAppliedConfigurationAnnotationKey,
+			constants.SourceNamespaceAnnotationKey, constants.SourceNameAnnotationKey, constants.SourceResourceVersionAnnotationKey,
+			constants.RuleAnnotationKey, constants.ContentHashAnnotationKey:
 			continue
 		}
 		copy[key] = value
@@ -38,11 +151,141 @@ func Manage(m map[string]string) map[string]string {
 	return m
 }
 
+// OwnerReference returns a controller reference to the given SecretSyncRule so that Kubernetes garbage collection
+// removes synced Secrets after the rule is deleted. Foreground deletion of the rule is blocked until its copies are gone.
 func OwnerReference(rule *typesv1.SecretSyncRule) metav1.OwnerReference {
-	return metav1.OwnerReference{
-		APIVersion: clientset.GroupName,
-		Kind:       clientset.SecretSyncRule,
-		Name:       rule.Name,
-		UID:        uuid.NewUUID(),
+	return *metav1.NewControllerRef(rule, clientset.SchemeGroupVersion.WithKind(clientset.SecretSyncRule))
+}
+
+// OwnerReferencesAreValid checks that every SecretSyncRule owner reference points at the given rule with its real UID,
+// and that there are none if the rule keeps its copies after it is deleted.
+func OwnerReferencesAreValid(references []metav1.OwnerReference, rule *typesv1.SecretSyncRule) bool {
+	if rule.Namespace != "" {
+		return true
+	}
+
+	if rule.Spec.RuleDeletionPolicy() != typesv1.DeletionPolicyDelete {
+		for _, reference := range references {
+			if reference.Kind == clientset.SecretSyncRule {
+				return false
+			}
+		}
+		return true
+	}
+
+	return ownerReferencesMatch(references, OwnerReference(rule))
+}
+
+// OwnerReferences returns the owner references of the Secrets synced by the given SecretSyncRule.
+// Secrets synced by a NamespacedSecretSyncRule have none, since Kubernetes does not allow owners in another namespace,
+// and neither do Secrets of a rule that keeps its copies after it is deleted, since garbage collection would remove them.
+func OwnerReferences(rule *typesv1.SecretSyncRule) []metav1.OwnerReference {
+	if rule.Namespace != "" || rule.Spec.RuleDeletionPolicy() != typesv1.DeletionPolicyDelete {
+		return nil
+	}
+
+	return []metav1.OwnerReference{OwnerReference(rule)}
+}
+
+// AnnotateDeletionPolicy records the rule deletion policy of the given SecretSyncRule in the given annotations,
+// so that it can still be applied to the synced copies after the rule is gone.
+func AnnotateDeletionPolicy(m map[string]string, rule *typesv1.SecretSyncRule) map[string]string {
+	if m == nil {
+		m = make(map[string]string)
+	}
+
+	if policy := rule.Spec.RuleDeletionPolicy(); policy != typesv1.DeletionPolicyDelete {
+		m[constants.DeletionPolicyAnnotationKey] = string(policy)
+	} else {
+		delete(m, constants.DeletionPolicyAnnotationKey)
+	}
+
+	return m
+}
+
+// AnnotateProvenance records the given source object and the key of the rule copying it in the given annotations,
+// so that a synced copy can be traced back to where it came from. Merged sources only record their name.
+func AnnotateProvenance(m map[string]string, rule string, source metav1.Object) map[string]string {
+	if m == nil {
+		m = make(map[string]string)
+	}
+
+	provenance := map[string]string{
+		constants.SourceNamespaceAnnotationKey:       source.GetNamespace(),
+		constants.SourceNameAnnotationKey:            source.GetName(),
+		constants.SourceResourceVersionAnnotationKey: source.GetResourceVersion(),
+		constants.RuleAnnotationKey:                  rule,
+	}
+	for key, value := range provenance {
+		if value != "" {
+			m[key] = value
+		} else {
+			delete(m, key)
+		}
+	}
+
+	return m
+}
+
+// RuleDeletionPolicyOf returns the deletion policy recorded on the given synced copy for when its rule is deleted.
+func RuleDeletionPolicyOf(secret *v1.Secret) typesv1.DeletionPolicyType {
+	return typesv1.DeletionPolicyType(secret.Annotations[constants.DeletionPolicyAnnotationKey]).OrDelete()
+}
+
+// Orphaned returns a copy of the given synced Secret without its managed-by, deletion policy and provenance annotations,
+// rule labels and rule owner references, so that it is no longer controlled by kube-secret-sync.
+func Orphaned(secret *v1.Secret) *v1.Secret {
+	orphaned := secret.DeepCopy()
+
+	delete(orphaned.Annotations, constants.ManagedByAnnotationKey)
+	delete(orphaned.Annotations, constants.DeletionPolicyAnnotationKey)
+	delete(orphaned.Annotations, constants.SourceNamespaceAnnotationKey)
+	delete(orphaned.Annotations, constants.SourceNameAnnotationKey)
+	delete(orphaned.Annotations, constants.SourceResourceVersionAnnotationKey)
+	delete(orphaned.Annotations, constants.RuleAnnotationKey)
+	delete(orphaned.Annotations, constants.ContentHashAnnotationKey)
+	delete(orphaned.Labels, constants.RuleLabelKey)
+	delete(orphaned.Labels, constants.RuleNamespaceLabelKey)
+	delete(orphaned.Labels, constants.RuleKindLabelKey)
+
+	orphaned.OwnerReferences = nil
+	for _, reference := range secret.OwnerReferences {
+		if reference.Kind != clientset.SecretSyncRule {
+			orphaned.OwnerReferences = append(orphaned.OwnerReferences, reference)
+		}
 	}
+
+	return orphaned
+}
+
+// ConfigMapOwnerReference returns a controller reference to the given ConfigMapSyncRule so that Kubernetes garbage collection
+// removes synced ConfigMaps after the rule is deleted.
+func ConfigMapOwnerReference(rule *typesv1.ConfigMapSyncRule) metav1.OwnerReference {
+	return *metav1.NewControllerRef(rule, clientset.SchemeGroupVersion.WithKind(clientset.ConfigMapSyncRule))
+}
+
+// ConfigMapOwnerReferencesAreValid checks that every ConfigMapSyncRule owner reference points at the given rule with its real UID.
+func ConfigMapOwnerReferencesAreValid(references []metav1.OwnerReference, rule *typesv1.ConfigMapSyncRule) bool {
+	return ownerReferencesMatch(references, ConfigMapOwnerReference(rule))
+}
+
+func ownerReferencesMatch(references []metav1.OwnerReference, expected metav1.OwnerReference) bool {
+	for _, reference := range references {
+		if reference.Kind == expected.Kind && !reflect.DeepEqual(reference, expected) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ResourceOwnerReference returns a controller reference to the given ResourceSyncRule so that Kubernetes garbage collection
+// removes synced resources after the rule is deleted.
+func ResourceOwnerReference(rule *typesv1.ResourceSyncRule) metav1.OwnerReference {
+	return *metav1.NewControllerRef(rule, clientset.SchemeGroupVersion.WithKind(clientset.ResourceSyncRule))
+}
+
+// ResourceOwnerReferencesAreValid checks that every ResourceSyncRule owner reference points at the given rule with its real UID.
+func ResourceOwnerReferencesAreValid(references []metav1.OwnerReference, rule *typesv1.ResourceSyncRule) bool {
+	return ownerReferencesMatch(references, ResourceOwnerReference(rule))
 }
@@ -4,13 +4,21 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	kssclientset "github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
-	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 )
 
 type Client struct {
@@ -20,12 +28,52 @@ type Client struct {
 	StartTime     time.Time
 
 	DefaultClientset        kubernetes.Interface
-	KubeSecretSyncClientset *kssclientset.KubeSecretSyncClientset
+	KubeSecretSyncClientset kssclientset.Interface
+	DynamicClientset        dynamic.Interface
+	RESTMapper              meta.RESTMapper
 
-	SecretWatcher         watch.Interface
-	NamespaceWatcher      watch.Interface
-	SecretSyncRuleWatcher watch.Interface
-	SignalChannel         chan os.Signal
+	InformerFactory        informers.SharedInformerFactory
+	SecretInformer         cache.SharedIndexInformer
+	NamespaceInformer      cache.SharedIndexInformer
+	SecretSyncRuleInformer cache.SharedIndexInformer
+
+	ConfigMapInformer         cache.SharedIndexInformer
+	ConfigMapSyncRuleInformer cache.SharedIndexInformer
+
+	SecretLister         corelisters.SecretLister
+	NamespaceLister      corelisters.NamespaceLister
+	SecretSyncRuleLister kssclientset.SecretSyncRuleLister
+
+	ConfigMapLister         corelisters.ConfigMapLister
+	ConfigMapSyncRuleLister kssclientset.ConfigMapSyncRuleLister
+
+	ResourceSyncRuleInformer cache.SharedIndexInformer
+	ResourceSyncRuleLister   kssclientset.ResourceSyncRuleLister
+
+	NamespacedSecretSyncRuleInformer cache.SharedIndexInformer
+	NamespacedSecretSyncRuleLister   kssclientset.NamespacedSecretSyncRuleLister
+	SecretSyncPolicyInformer         cache.SharedIndexInformer
+	SecretSyncPolicyLister           kssclientset.SecretSyncPolicyLister
+
+	// DynamicInformerFactory lazily starts an informer for every kind referenced by a ResourceSyncRule.
+	DynamicInformerFactory   dynamicinformer.DynamicSharedInformerFactory
+	resourceInformers        map[schema.GroupVersionResource]informers.GenericInformer
+	resourceInformerFailures map[schema.GroupVersionResource]resourceInformerFailure
+	resourceInformersLock    sync.Mutex
+
+	// verifiedCopies lets syncs trust the content hash recorded on synced copies that were not changed by anyone else.
+	verifiedCopies verifiedCopies
+
+	// NewRemoteClientset creates the clientsets of the remote clusters that SecretSyncRules sync to.
+	NewRemoteClientset RemoteClientsetFunc
+	remoteClusters     map[string]*remoteCluster
+	remoteClustersLock sync.Mutex
+
+	// SourceProviders read the data of external sources by the provider name they are referenced by.
+	SourceProviders map[string]SourceProvider
+
+	Queue         workqueue.RateLimitingInterface
+	SignalChannel chan os.Signal
 }
 
 func (client *Client) Initialize(config *SyncConfig) error {
@@ -37,10 +85,13 @@ func (client *Client) Initialize(config *SyncConfig) error {
 		return err
 	}
 
-	if err := client.InitializeWatchers(); err != nil {
+	if err := client.InitializeInformers(); err != nil {
 		return err
 	}
 
+	client.InitializeSourceProviders()
+	client.InitializeQueue()
+	client.AddEventHandlers()
 	client.InitializeSignalChannel()
 
 	return nil
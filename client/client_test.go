@@ -2,18 +2,89 @@ package client_test
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	kssfake "github.com/alehechka/kube-secret-sync/api/types/v1/clientset/fake"
 	"github.com/alehechka/kube-secret-sync/client"
+	"github.com/alehechka/kube-secret-sync/constants"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
-func InitializeTestClientset() *client.Client {
+// InitializeTestClientset creates a Client backed by fake clientsets seeded with the given objects
+// and waits for its informer caches to sync.
+func InitializeTestClientset(objects ...runtime.Object) *client.Client {
+	var coreObjects, ruleObjects, dynamicObjects []runtime.Object
+	for _, obj := range objects {
+		switch obj.(type) {
+		case *typesv1.SecretSyncRule, *typesv1.ConfigMapSyncRule, *typesv1.ResourceSyncRule, *typesv1.NamespacedSecretSyncRule, *typesv1.SecretSyncPolicy:
+			ruleObjects = append(ruleObjects, obj)
+		case *unstructured.Unstructured:
+			dynamicObjects = append(dynamicObjects, obj)
+		default:
+			coreObjects = append(coreObjects, obj)
+		}
+	}
+
 	c := new(client.Client)
 
+	c.SyncConfig = &client.SyncConfig{}
 	c.Context = context.Background()
 	c.StartTime = time.Now()
-	c.DefaultClientset = fake.NewSimpleClientset()
+	c.DefaultClientset = fake.NewSimpleClientset(coreObjects...)
+	c.KubeSecretSyncClientset = kssfake.NewSimpleClientset(ruleObjects...)
+	c.DynamicClientset = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), testListKinds, dynamicObjects...)
+	c.RESTMapper = testRESTMapper()
+
+	if err := c.InitializeInformers(); err != nil {
+		panic(err)
+	}
+	c.InitializeQueue()
+	c.StartInformers(c.Context.Done())
+
+	return c
+}
 
+// WithRemoteClusters makes the given Client connect to the given fake clientsets,
+// keyed by the contents of the kubeconfig of the remote cluster they stand in for.
+func WithRemoteClusters(c *client.Client, remotes map[string]kubernetes.Interface) *client.Client {
+	c.NewRemoteClientset = func(kubeconfig []byte) (kubernetes.Interface, error) {
+		remote, ok := remotes[string(kubeconfig)]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown cluster %s", constants.ErrInvalidKubeconfig, kubeconfig)
+		}
+		return remote, nil
+	}
 	return c
 }
+
+// testListKinds maps the resources served by the fake dynamic clientset to their list kinds.
+var testListKinds = map[schema.GroupVersionResource]string{
+	networkPolicyResource: "NetworkPolicyList",
+	clusterRoleResource:   "ClusterRoleList",
+}
+
+// testRESTMapper maps the kinds served by the fake dynamic clientset to their resources.
+func testRESTMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.AddSpecific(networkPolicyKind, networkPolicyResource, networkPolicyResource, meta.RESTScopeNamespace)
+	mapper.AddSpecific(clusterRoleKind, clusterRoleResource, clusterRoleResource, meta.RESTScopeRoot)
+	return mapper
+}
+
+// PrepareSecret prepares the synced copy of the given Secret and panics if the rule cannot render it.
+func PrepareSecret(rule *typesv1.SecretSyncRule, namespace *v1.Namespace, secret *v1.Secret) *v1.Secret {
+	prepared, err := client.PrepareSecret(rule, namespace, secret)
+	if err != nil {
+		panic(err)
+	}
+	return prepared
+}
@@ -2,8 +2,11 @@ package client
 
 import (
 	"github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -21,6 +24,12 @@ func (client *Client) InitializeClientsets() error {
 		return err
 	}
 
+	if err := client.InitializeDynamic(); err != nil {
+		return err
+	}
+
+	client.NewRemoteClientset = NewRemoteClientset
+
 	return nil
 }
 
@@ -34,6 +43,17 @@ func (client *Client) InitializeKubeSecretSync() (err error) {
 	return
 }
 
+// InitializeDynamic creates the dynamic clientset and the discovery backed RESTMapper used to sync ResourceSyncRules.
+func (client *Client) InitializeDynamic() (err error) {
+	client.DynamicClientset, err = dynamic.NewForConfig(client.ClusterConfig)
+	if err != nil {
+		return
+	}
+
+	client.RESTMapper = restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(client.DefaultClientset.Discovery()))
+	return
+}
+
 func (client *Client) InitializeClusterConfig() (err error) {
 	if client.SyncConfig.OutOfCluster {
 		client.ClusterConfig, err = clientcmd.BuildConfigFromFlags("", client.SyncConfig.KubeConfig)
@@ -0,0 +1,350 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
+	"github.com/alehechka/kube-secret-sync/constants"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// RemoteClusterTimeout bounds every request to a remote cluster, so that an unreachable cluster cannot block the worker syncing its rule.
+const RemoteClusterTimeout = 30 * time.Second
+
+// RemoteClientsetFunc creates the clientset of a remote cluster from the contents of its kubeconfig.
+type RemoteClientsetFunc func(kubeconfig []byte) (kubernetes.Interface, error)
+
+// NewRemoteClientset creates the clientset of a remote cluster from the contents of its kubeconfig.
+// Only kubeconfigs accepted by ValidateKubeconfig are used.
+func NewRemoteClientset(kubeconfig []byte) (kubernetes.Interface, error) {
+	loaded, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", constants.ErrInvalidKubeconfig, err.Error())
+	}
+
+	if err := ValidateKubeconfig(loaded); err != nil {
+		return nil, err
+	}
+
+	config, err := clientcmd.NewDefaultClientConfig(*loaded, nil).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", constants.ErrInvalidKubeconfig, err.Error())
+	}
+	config.Timeout = RemoteClusterTimeout
+
+	return kubernetes.NewForConfig(config)
+}
+
+// ValidateKubeconfig rejects kubeconfigs that do not hold all of their credentials inline.
+// Kubeconfigs are read from Secrets that the controller does not control, so exec and auth provider plugins,
+// which would run in the controller, and file references, which would read files of the controller such as its
+// ServiceAccount token, are not allowed.
+func ValidateKubeconfig(config *clientcmdapi.Config) error {
+	for name, cluster := range config.Clusters {
+		if cluster.CertificateAuthority != "" {
+			return fmt.Errorf("%w: cluster %s references the file %s, use certificate-authority-data instead", constants.ErrInvalidKubeconfig, name, cluster.CertificateAuthority)
+		}
+	}
+
+	for name, user := range config.AuthInfos {
+		switch {
+		case user.Exec != nil:
+			return fmt.Errorf("%w: user %s uses an exec plugin", constants.ErrInvalidKubeconfig, name)
+		case user.AuthProvider != nil:
+			return fmt.Errorf("%w: user %s uses an auth provider", constants.ErrInvalidKubeconfig, name)
+		case user.TokenFile != "":
+			return fmt.Errorf("%w: user %s references the file %s, use token instead", constants.ErrInvalidKubeconfig, name, user.TokenFile)
+		case user.ClientCertificate != "":
+			return fmt.Errorf("%w: user %s references the file %s, use client-certificate-data instead", constants.ErrInvalidKubeconfig, name, user.ClientCertificate)
+		case user.ClientKey != "":
+			return fmt.Errorf("%w: user %s references the file %s, use client-key-data instead", constants.ErrInvalidKubeconfig, name, user.ClientKey)
+		}
+	}
+
+	return nil
+}
+
+// remoteCluster is the clientset of a remote cluster together with the kubeconfig it was created from.
+type remoteCluster struct {
+	kubeconfig []byte
+	clientset  kubernetes.Interface
+}
+
+// RemoteClientset returns the clientset of the given Cluster, read from its cached kubeconfig Secret.
+// One clientset is kept per kubeconfig and recreated whenever the kubeconfig changes.
+func (client *Client) RemoteClientset(cluster typesv1.Cluster) (kubernetes.Interface, error) {
+	reference := cluster.Secret.Namespace + "/" + cluster.Secret.Name
+
+	secret, err := client.SecretLister.Secrets(cluster.Secret.Namespace).Get(cluster.Secret.Name)
+	if errors.IsNotFound(err) {
+		return nil, fmt.Errorf("%s: %w", reference, constants.ErrKubeconfigNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	kubeconfig, ok := secret.Data[cluster.KubeconfigKey()]
+	if !ok {
+		return nil, fmt.Errorf("%s[%s]: %w", reference, cluster.KubeconfigKey(), constants.ErrKubeconfigNotFound)
+	}
+
+	key := kubeconfigReference(cluster)
+
+	client.remoteClustersLock.Lock()
+	defer client.remoteClustersLock.Unlock()
+
+	if cached, ok := client.remoteClusters[key]; ok && bytes.Equal(cached.kubeconfig, kubeconfig) {
+		return cached.clientset, nil
+	}
+
+	newRemoteClientset := client.NewRemoteClientset
+	if newRemoteClientset == nil {
+		newRemoteClientset = NewRemoteClientset
+	}
+
+	remote, err := newRemoteClientset(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.remoteClusters == nil {
+		client.remoteClusters = make(map[string]*remoteCluster)
+	}
+	client.remoteClusters[key] = &remoteCluster{kubeconfig: kubeconfig, clientset: remote}
+
+	return remote, nil
+}
+
+// syncRemoteCluster syncs the given source Secrets to every Namespace of the given remote Cluster that the rule allows,
+// and removes the copies of the rule in that cluster that are no longer desired.
+// Remote clusters are not watched, so their Namespaces and Secrets are read from the API server on every sync.
+func (client *Client) syncRemoteCluster(rule *typesv1.SecretSyncRule, cluster typesv1.Cluster, secrets []*v1.Secret, summary *ClusterSummary) error {
+	logger := clusterLogger(rule, cluster.Name)
+
+	remote, err := client.RemoteClientset(cluster)
+	if err != nil {
+		logger.Errorf("failed to create clientset: %s", err.Error())
+		summary.Error = err.Error()
+		return err
+	}
+
+	list, err := remote.CoreV1().Namespaces().List(client.Context, metav1.ListOptions{})
+	if err != nil {
+		logger.Errorf("failed to list namespaces: %s", err.Error())
+		summary.Error = err.Error()
+		return err
+	}
+	summary.Connected = true
+
+	var namespaces []*v1.Namespace
+	for i := range list.Items {
+		switch namespace := &list.Items[i]; {
+		case rule.ShouldSyncRemoteNamespace(namespace):
+			namespaces = append(namespaces, namespace)
+		case rule.IsRefusedByRemoteNamespace(namespace):
+			summary.RefusedNamespaces = append(summary.RefusedNamespaces, namespace.Name)
+		}
+	}
+	summary.Targets = len(namespaces)
+
+	sync := func(rule *typesv1.SecretSyncRule, namespace *v1.Namespace, secret *v1.Secret) (SyncAction, error) {
+		return client.SyncRemoteSecret(remote, rule, namespace, secret)
+	}
+	desired, errs := syncSecretsToNamespaces(rule, namespaces, secrets, &summary.SyncSummary, sync)
+
+	targeted := sets.NewString()
+	for _, namespace := range namespaces {
+		targeted.Insert(namespace.Name)
+	}
+
+	// like SourceDeletionPolicy, copies in namespaces that the rule no longer targets are always deleted
+	policy := func(secret *v1.Secret) typesv1.DeletionPolicyType {
+		if targeted.Has(secret.Namespace) {
+			return rule.Spec.SourceDeletionPolicy()
+		}
+		return typesv1.DeletionPolicyDelete
+	}
+
+	if err := client.PruneRemoteSecrets(remote, rule, desired, policy, &summary.SyncSummary); err != nil {
+		errs = append(errs, err)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// RemovedClusters returns the remote clusters recorded in the status of the given SecretSyncRule
+// that neither its name nor its kubeconfig is listed in the spec of the rule anymore.
+func RemovedClusters(rule *typesv1.SecretSyncRule) (clusters []typesv1.Cluster) {
+	names, kubeconfigs := sets.NewString(), sets.NewString()
+	for _, cluster := range rule.Spec.Clusters {
+		names.Insert(cluster.Name)
+		kubeconfigs.Insert(kubeconfigReference(cluster))
+	}
+
+	for _, status := range rule.Status.Clusters {
+		if status.Secret == nil || names.Has(status.Name) {
+			continue
+		}
+
+		cluster := typesv1.Cluster{Name: status.Name, Secret: *status.Secret, Key: status.Key}
+		if !kubeconfigs.Has(kubeconfigReference(cluster)) {
+			clusters = append(clusters, cluster)
+		}
+	}
+
+	return
+}
+
+// kubeconfigReference returns the key of the kubeconfig of the given Cluster.
+func kubeconfigReference(cluster typesv1.Cluster) string {
+	return cluster.Secret.Namespace + "/" + cluster.Secret.Name + "/" + cluster.KubeconfigKey()
+}
+
+// pruneRemovedCluster deletes the copies of the given rule in a remote cluster that was removed from the rule.
+// Clusters whose kubeconfig can no longer be read are given up on and their copies are left orphaned;
+// other failures keep the cluster in the status of the rule so that pruning is retried on the next sync.
+func (client *Client) pruneRemovedCluster(rule *typesv1.SecretSyncRule, cluster typesv1.Cluster, summary *SyncSummary) error {
+	logger := clusterLogger(rule, cluster.Name)
+
+	remote, err := client.RemoteClientset(cluster)
+	if IsRuleError(err) {
+		logger.Warnf("orphaning copies in removed cluster: %s", err.Error())
+		return nil
+	}
+	if err == nil {
+		logger.Infof("removing copies from removed cluster")
+		policy := func(*v1.Secret) typesv1.DeletionPolicyType { return typesv1.DeletionPolicyDelete }
+		err = client.PruneRemoteSecrets(remote, rule, nil, policy, summary)
+	}
+	if err != nil {
+		logger.Errorf("failed to remove copies from removed cluster: %s", err.Error())
+		summary.Cluster(cluster).Error = fmt.Sprintf("removed from the rule, failed to remove copies: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// SyncRemoteSecret creates or updates the copy of the given Secret in the given Namespace of a remote cluster and returns the action taken.
+// Copies in remote clusters carry no owner references, since the rule does not exist in those clusters.
+func (client *Client) SyncRemoteSecret(remote kubernetes.Interface, rule *typesv1.SecretSyncRule, namespace *v1.Namespace, secret *v1.Secret) (SyncAction, error) {
+	prepared, err := PrepareSecret(rule, namespace, secret)
+	if err != nil {
+		return SyncActionNone, err
+	}
+	prepared.OwnerReferences = nil
+
+	logger := secretLogger(prepared)
+	secrets := remote.CoreV1().Secrets(namespace.Name)
+
+	existing, err := secrets.Get(client.Context, prepared.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		logger.Infof("creating remote secret")
+		created, err := secrets.Create(client.Context, prepared, metav1.CreateOptions{})
+		if err == nil {
+			client.verifiedCopies.record(created)
+		}
+		return SyncActionCreated, err
+	}
+	if err != nil {
+		return SyncActionNone, err
+	}
+
+	if !IsManagedBy(existing) {
+		policy := rule.Spec.Rules.OnConflict()
+		action, resolved := ResolveConflict(policy, prepared, existing)
+		logConflict(logger, policy, action)
+
+		if resolved == nil {
+			return action, nil
+		}
+		resolved.ResourceVersion = existing.ResourceVersion
+		_, err = secrets.Update(client.Context, resolved, metav1.UpdateOptions{})
+		return action, err
+	}
+
+	if client.SyncedByOtherExistingRule(existing, rule) {
+		logger.Warnf("existing remote secret is managed by another rule and was left untouched")
+		return SyncActionConflict, nil
+	}
+
+	if client.SecretIsUpToDate(existing, prepared, &rule.Spec.Rules) {
+		logger.Debugf("existing remote secret contains same data")
+		return SyncActionNone, nil
+	}
+
+	logger.Infof("updating remote secret")
+	prepared.ResourceVersion = existing.ResourceVersion
+	updated, err := secrets.Update(client.Context, prepared, metav1.UpdateOptions{})
+	if err == nil {
+		client.verifiedCopies.record(updated)
+	}
+	return SyncActionUpdated, err
+}
+
+// PruneRemoteSecrets removes every Secret in a remote cluster synced by the given rule that is not part of the desired set of synced Secrets,
+// applying the deletion policy returned by the given function to each of them.
+// Like PruneOwnedSecrets, copies that are no longer managed are only deleted when the rules overwrite conflicting Secrets.
+func (client *Client) PruneRemoteSecrets(remote kubernetes.Interface, rule *typesv1.SecretSyncRule, desired map[types.NamespacedName]bool,
+	policy func(secret *v1.Secret) typesv1.DeletionPolicyType, summary *SyncSummary) error {
+	selector := labels.SelectorFromSet(labels.Set{constants.RuleLabelKey: rule.Name})
+
+	list, err := remote.CoreV1().Secrets(metav1.NamespaceAll).List(client.Context, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range list.Items {
+		secret := &list.Items[i]
+
+		if !sets.NewString(syncedByRuleNames(secret, clientset.SecretSyncRule)...).Has(ruleKey(rule)) {
+			continue
+		}
+
+		if desired[types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}] {
+			continue
+		}
+
+		switch policy(secret) {
+		case typesv1.DeletionPolicyRetain:
+			secretLogger(secret).Debugf("retaining remote secret that is no longer synced")
+			continue
+		case typesv1.DeletionPolicyOrphan:
+			secretLogger(secret).Infof("orphaning remote secret")
+			if _, err := remote.CoreV1().Secrets(secret.Namespace).Update(client.Context, Orphaned(secret), metav1.UpdateOptions{}); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			summary.Add(SyncActionOrphaned)
+			continue
+		}
+
+		if rule.Spec.Rules.OnConflict() != typesv1.ConflictPolicyOverwrite && !IsManagedBy(secret) {
+			secretLogger(secret).Debugf("existing remote secret is not managed and will not be deleted")
+			continue
+		}
+
+		secretLogger(secret).Infof("deleting remote secret")
+		err := remote.CoreV1().Secrets(secret.Namespace).Delete(client.Context, secret.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, err)
+			continue
+		}
+		summary.Add(SyncActionDeleted)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
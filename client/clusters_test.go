@@ -0,0 +1,223 @@
+package client_test
+
+import (
+	"testing"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	pkg "github.com/alehechka/kube-secret-sync/client"
+	"github.com/alehechka/kube-secret-sync/constants"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_SyncSecretSyncRule_RemoteCluster(t *testing.T) {
+	remote := fake.NewSimpleClientset(defaultNamespace, testNamespace)
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, remoteKubeconfig, testClusterSecretSyncRule)
+	WithRemoteClusters(client, map[string]kubernetes.Interface{keyRemoteCluster: remote})
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	// the default namespace of the remote cluster is not the source namespace, so it is synced to as well
+	for _, namespace := range []string{keyDefault, keyTestNamespace} {
+		secret, err := remote.CoreV1().Secrets(namespace).Get(client.Context, keyDefaultSecret, metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.True(t, pkg.IsManagedBy(secret))
+		assert.Empty(t, secret.OwnerReferences)
+		assert.Equal(t, keyTestSecretSyncRule, secret.Labels[constants.RuleLabelKey])
+	}
+
+	rule, err := client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, meta.IsStatusConditionTrue(rule.Status.Conditions, typesv1.ConditionReady))
+	assert.Len(t, rule.Status.Clusters, 1)
+	assert.True(t, rule.Status.Clusters[0].Connected)
+	assert.Equal(t, []string{keyDefault, keyTestNamespace}, rule.Status.Clusters[0].SyncedNamespaces)
+}
+
+func Test_SyncSecretSyncRule_RemoteClusterPrune(t *testing.T) {
+	rule := testClusterSecretSyncRule.DeepCopy()
+	rule.Spec.Rules.Namespaces.Exclude = []string{keyDefault}
+
+	stale := PrepareSecret(rule, defaultNamespace, defaultSecret)
+	stale.OwnerReferences = nil
+
+	remote := fake.NewSimpleClientset(defaultNamespace, testNamespace, stale)
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, remoteKubeconfig, rule)
+	WithRemoteClusters(client, map[string]kubernetes.Interface{keyRemoteCluster: remote})
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	_, err = remote.CoreV1().Secrets(keyDefault).Get(client.Context, keyDefaultSecret, metav1.GetOptions{})
+	assert.Error(t, err)
+
+	_, err = remote.CoreV1().Secrets(keyTestNamespace).Get(client.Context, keyDefaultSecret, metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func Test_SyncSecretSyncRule_RemovedCluster(t *testing.T) {
+	rule := testClusterSecretSyncRule.DeepCopy()
+	cluster := rule.Spec.Clusters[0]
+	rule.Spec.Clusters = nil
+	rule.Status.Clusters = []typesv1.ClusterStatus{{Name: cluster.Name, Secret: &cluster.Secret, Connected: true}}
+
+	synced := PrepareSecret(testClusterSecretSyncRule, testNamespace, defaultSecret)
+	synced.OwnerReferences = nil
+
+	remote := fake.NewSimpleClientset(testNamespace, synced)
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, remoteKubeconfig, rule)
+	WithRemoteClusters(client, map[string]kubernetes.Interface{keyRemoteCluster: remote})
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	_, err = remote.CoreV1().Secrets(keyTestNamespace).Get(client.Context, keyDefaultSecret, metav1.GetOptions{})
+	assert.Error(t, err)
+
+	rule, err = client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, rule.Status.Clusters)
+}
+
+func Test_SyncSecretSyncRule_RemoteClusterRecordsKubeconfig(t *testing.T) {
+	remote := fake.NewSimpleClientset(testNamespace)
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, remoteKubeconfig, testClusterSecretSyncRule)
+	WithRemoteClusters(client, map[string]kubernetes.Interface{keyRemoteCluster: remote})
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	rule, err := client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, rule.Status.Clusters, 1)
+	assert.Equal(t, &testClusterSecretSyncRule.Spec.Clusters[0].Secret, rule.Status.Clusters[0].Secret)
+	assert.Empty(t, pkg.RemovedClusters(rule))
+}
+
+func Test_SyncSecretSyncRule_RemoteClusterSourceDeletionPolicy(t *testing.T) {
+	rule := testClusterSecretSyncRule.DeepCopy()
+	rule.Spec.DeletionPolicy = &typesv1.DeletionPolicy{OnSourceDeletion: typesv1.DeletionPolicyRetain}
+
+	synced := PrepareSecret(rule, testNamespace, defaultSecret)
+	synced.OwnerReferences = nil
+
+	remote := fake.NewSimpleClientset(testNamespace, synced)
+	client := InitializeTestClientset(defaultNamespace, testNamespace, remoteKubeconfig, rule)
+	WithRemoteClusters(client, map[string]kubernetes.Interface{keyRemoteCluster: remote})
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err := remote.CoreV1().Secrets(keyTestNamespace).Get(client.Context, keyDefaultSecret, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, pkg.IsManagedBy(secret))
+}
+
+func Test_SyncSecretSyncRule_RemoteClusterUnmanaged(t *testing.T) {
+	unmanaged := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: keyDefaultSecret, Namespace: keyTestNamespace}}
+
+	rule := testClusterSecretSyncRule.DeepCopy()
+	rule.Spec.Rules.ConflictPolicy = typesv1.ConflictPolicyFail
+
+	remote := fake.NewSimpleClientset(testNamespace, unmanaged)
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, remoteKubeconfig, rule)
+	WithRemoteClusters(client, map[string]kubernetes.Interface{keyRemoteCluster: remote})
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err := remote.CoreV1().Secrets(keyTestNamespace).Get(client.Context, keyDefaultSecret, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.False(t, pkg.IsManagedBy(secret))
+
+	rule, err = client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, meta.IsStatusConditionTrue(rule.Status.Conditions, typesv1.ConditionDegraded))
+	assert.Equal(t, pkg.ReasonSecretNotManaged, rule.Status.Clusters[0].Failures[0].Reason)
+}
+
+func Test_SyncSecretSyncRule_RemoteClusterKubeconfigNotFound(t *testing.T) {
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, testClusterSecretSyncRule)
+	WithRemoteClusters(client, map[string]kubernetes.Interface{})
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	_, err = client.DefaultClientset.CoreV1().Secrets(keyTestNamespace).Get(client.Context, keyDefaultSecret, metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	rule, err := client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.False(t, meta.IsStatusConditionTrue(rule.Status.Conditions, typesv1.ConditionReady))
+	assert.False(t, rule.Status.Clusters[0].Connected)
+	assert.Contains(t, rule.Status.Clusters[0].Message, "kubeconfig not found")
+}
+
+func Test_RemoteClientset_RecreatedOnKubeconfigChange(t *testing.T) {
+	first, second := fake.NewSimpleClientset(), fake.NewSimpleClientset()
+	client := InitializeTestClientset(remoteKubeconfig)
+	WithRemoteClusters(client, map[string]kubernetes.Interface{keyRemoteCluster: first, "rotated": second})
+
+	cluster := testClusterSecretSyncRule.Spec.Clusters[0]
+
+	remote, err := client.RemoteClientset(cluster)
+	assert.NoError(t, err)
+	assert.Same(t, first, remote)
+
+	rotated := remoteKubeconfig.DeepCopy()
+	rotated.Data[typesv1.DefaultKubeconfigKey] = []byte("rotated")
+	assert.NoError(t, client.SecretInformer.GetIndexer().Update(rotated))
+
+	remote, err = client.RemoteClientset(cluster)
+	assert.NoError(t, err)
+	assert.Same(t, second, remote)
+}
+
+// testKubeconfig returns a kubeconfig for a single cluster and user with the given additional cluster and user fields.
+func testKubeconfig(cluster, user string) []byte {
+	return []byte(`apiVersion: v1
+kind: Config
+clusters:
+  - name: spoke
+    cluster:
+      server: https://spoke.example.com
+` + cluster + `
+users:
+  - name: spoke
+    user:
+      token: secret
+` + user + `
+contexts:
+  - name: spoke
+    context:
+      cluster: spoke
+      user: spoke
+current-context: spoke
+`)
+}
+
+func Test_NewRemoteClientset(t *testing.T) {
+	remote, err := pkg.NewRemoteClientset(testKubeconfig("      insecure-skip-tls-verify: true", ""))
+	assert.NoError(t, err)
+	assert.NotNil(t, remote)
+}
+
+func Test_NewRemoteClientset_RejectsNonInlineCredentials(t *testing.T) {
+	for _, kubeconfig := range [][]byte{
+		testKubeconfig("      certificate-authority: /var/run/secrets/kubernetes.io/serviceaccount/ca.crt", ""),
+		testKubeconfig("", "      tokenFile: /var/run/secrets/kubernetes.io/serviceaccount/token"),
+		testKubeconfig("", "      client-certificate: /etc/passwd"),
+		testKubeconfig("", "      client-key: /etc/passwd"),
+		testKubeconfig("", "      exec:\n        apiVersion: client.authentication.k8s.io/v1\n        command: sh"),
+		testKubeconfig("", "      auth-provider:\n        name: gcp"),
+		[]byte("not a kubeconfig"),
+	} {
+		_, err := pkg.NewRemoteClientset(kubeconfig)
+		assert.ErrorIs(t, err, constants.ErrInvalidKubeconfig, string(kubeconfig))
+	}
+}
@@ -1,9 +1,22 @@
 package client
 
+import "time"
+
 // SyncConfig contains the configuration options for the SyncSecrets operation.
 type SyncConfig struct {
 	PodNamespace string
 
 	OutOfCluster bool
 	KubeConfig   string
+
+	ResyncInterval time.Duration
+
+	FileSourceDirectory    string
+	FileSourcePollInterval time.Duration
+
+	LeaderElect   bool
+	LeaseName     string
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
 }
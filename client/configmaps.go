@@ -0,0 +1,353 @@
+package client
+
+import (
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
+	"github.com/alehechka/kube-secret-sync/constants"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/cache"
+)
+
+func (client *Client) ConfigMapEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    client.AddedConfigMapHandler,
+		UpdateFunc: client.ModifiedConfigMapHandler,
+		DeleteFunc: client.DeletedConfigMapHandler,
+	}
+}
+
+func (client *Client) AddedConfigMapHandler(obj interface{}) {
+	configMap, ok := obj.(*v1.ConfigMap)
+	if !ok {
+		log.Error("failed to cast ConfigMap")
+		return
+	}
+
+	if IsManagedBy(configMap) {
+		return
+	}
+
+	if configMap.CreationTimestamp.Time.Before(client.StartTime) {
+		configMapLogger(configMap).Debugf("configmap will be synced on startup by ConfigMapSyncRule informer")
+		return
+	}
+
+	client.EnqueueConfigMap(configMap, "added")
+}
+
+func (client *Client) ModifiedConfigMapHandler(oldObj, newObj interface{}) {
+	configMap, ok := newObj.(*v1.ConfigMap)
+	if !ok {
+		log.Error("failed to cast ConfigMap")
+		return
+	}
+
+	if configMap.DeletionTimestamp != nil {
+		return
+	}
+
+	if old, ok := oldObj.(*v1.ConfigMap); ok && old.ResourceVersion == configMap.ResourceVersion {
+		return
+	}
+
+	if IsManagedBy(configMap) {
+		client.EnqueueDriftedConfigMap(configMap, false)
+		return
+	}
+
+	client.EnqueueConfigMap(configMap, "modified")
+}
+
+func (client *Client) DeletedConfigMapHandler(obj interface{}) {
+	configMap, ok := objectFromTombstone(obj).(*v1.ConfigMap)
+	if !ok {
+		log.Error("failed to cast ConfigMap")
+		return
+	}
+
+	if IsManagedBy(configMap) {
+		client.verifiedCopies.forget(configMap)
+		client.EnqueueDriftedConfigMap(configMap, true)
+		return
+	}
+
+	client.EnqueueConfigMap(configMap, "deleted")
+}
+
+// EnqueueConfigMap adds every ConfigMapSyncRule that syncs the given ConfigMap to the work queue.
+func (client *Client) EnqueueConfigMap(configMap *v1.ConfigMap, event string) {
+	if client.enqueueMatchingConfigMapRules(func(rule *typesv1.ConfigMapSyncRule) bool { return rule.ShouldSyncConfigMap(configMap) }) > 0 {
+		configMapLogger(configMap).Infof(event)
+	}
+}
+
+// EnqueueDriftedConfigMap adds the ConfigMapSyncRules owning the given managed ConfigMap to the work queue
+// if the ConfigMap was deleted while still targeted or no longer matches its source.
+// Writes made by the controller itself leave the copy equal to its source and are therefore ignored.
+func (client *Client) EnqueueDriftedConfigMap(configMap *v1.ConfigMap, deleted bool) {
+	logger := configMapLogger(configMap)
+
+	for _, name := range ownerRuleNames(configMap, clientset.ConfigMapSyncRule) {
+		rule, err := client.ConfigMapSyncRuleLister.Get(name)
+		if err != nil || rule.DeletionTimestamp != nil {
+			continue
+		}
+
+		source, ok := client.SourceConfigMapFor(rule, configMap)
+		if !ok {
+			continue
+		}
+
+		namespace, err := client.NamespaceLister.Get(configMap.Namespace)
+		if err != nil {
+			continue
+		}
+
+		if deleted {
+			if !rule.ShouldSyncNamespace(namespace) {
+				continue
+			}
+			logger.Infof("deleted while still targeted by ConfigMapSyncRule %s, restoring", rule.Name)
+		} else {
+			if client.ConfigMapIsUpToDate(configMap, PrepareConfigMap(rule, namespace, source)) {
+				continue
+			}
+			logger.Infof("drifted from source of ConfigMapSyncRule %s, restoring", rule.Name)
+		}
+
+		client.EnqueueConfigMapSyncRule(rule)
+	}
+}
+
+// SourceConfigMaps returns the cached source ConfigMaps of the given ConfigMapSyncRule,
+// along with the referenced ConfigMaps that do not exist.
+func (client *Client) SourceConfigMaps(rule *typesv1.ConfigMapSyncRule) (configMaps []*v1.ConfigMap, missing []typesv1.ConfigMap, err error) {
+	seen := make(map[typesv1.ConfigMap]bool)
+
+	for _, reference := range rule.Spec.ConfigMapReferences() {
+		if seen[reference] {
+			continue
+		}
+		seen[reference] = true
+
+		configMap, err := client.ConfigMapLister.ConfigMaps(reference.Namespace).Get(reference.Name)
+		if errors.IsNotFound(err) {
+			missing = append(missing, reference)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		configMaps = append(configMaps, configMap)
+	}
+
+	return
+}
+
+// SourceConfigMapFor returns the cached source ConfigMap of the given ConfigMapSyncRule that the given synced ConfigMap was copied from.
+func (client *Client) SourceConfigMapFor(rule *typesv1.ConfigMapSyncRule, configMap *v1.ConfigMap) (*v1.ConfigMap, bool) {
+	sources, _, err := client.SourceConfigMaps(rule)
+	if err != nil {
+		return nil, false
+	}
+
+	for _, source := range sources {
+		if source.Name == configMap.Name {
+			return source, true
+		}
+	}
+
+	return nil, false
+}
+
+// SyncConfigMap creates or updates the copy of the given ConfigMap in the given Namespace and returns the action taken.
+func (client *Client) SyncConfigMap(rule *typesv1.ConfigMapSyncRule, namespace *v1.Namespace, configMap *v1.ConfigMap) (SyncAction, error) {
+	logger := configMapLogger(configMap, namespace)
+	prepared := PrepareConfigMap(rule, namespace, configMap)
+
+	if namespaceConfigMap, err := client.ConfigMapLister.ConfigMaps(namespace.Name).Get(configMap.Name); err == nil {
+		logger.Debugf("already exists")
+
+		if !rule.Spec.Rules.Force && !IsManagedBy(namespaceConfigMap) {
+			logger.Debugf("existing configmap is not managed and will not be force updated")
+			return SyncActionSkipped, nil
+		}
+
+		if IsManagedBy(namespaceConfigMap) && ConfigMapSyncedByOtherRule(namespaceConfigMap, rule) {
+			logger.Warnf("existing configmap is managed by another rule and was left untouched")
+			return SyncActionConflict, nil
+		}
+
+		if IsManagedBy(namespaceConfigMap) && client.ConfigMapIsUpToDate(namespaceConfigMap, prepared) &&
+			ConfigMapOwnerReferencesAreValid(namespaceConfigMap.OwnerReferences, rule) {
+			logger.Debugf("existing configmap contains same data")
+			return SyncActionNone, nil
+		}
+
+		return SyncActionUpdated, client.UpdateConfigMap(prepared)
+	}
+
+	return SyncActionCreated, client.CreateConfigMap(prepared)
+}
+
+// DeleteSyncedConfigMap deletes the given synced ConfigMap in the given Namespace if it is managed or the rules force it,
+// and returns the action taken.
+func (client *Client) DeleteSyncedConfigMap(rules typesv1.ConfigMapRules, namespace *v1.Namespace, configMap *v1.ConfigMap) (SyncAction, error) {
+	logger := configMapLogger(configMap, namespace)
+
+	if namespaceConfigMap, err := client.ConfigMapLister.ConfigMaps(namespace.Name).Get(configMap.Name); err == nil {
+		if rules.Force || IsManagedBy(namespaceConfigMap) {
+			return SyncActionDeleted, client.DeleteConfigMap(namespace, configMap)
+		}
+
+		logger.Debugf("existing configmap is not managed and will not be force deleted")
+		return SyncActionSkipped, nil
+	}
+
+	return SyncActionNone, nil
+}
+
+// PruneOwnedConfigMaps deletes every ConfigMap synced by the ConfigMapSyncRule with the given name
+// that is not part of the desired set of synced ConfigMaps.
+func (client *Client) PruneOwnedConfigMaps(ruleName string, rules typesv1.ConfigMapRules, desired map[types.NamespacedName]bool, summary *SyncSummary) error {
+	configMaps, err := client.OwnedConfigMaps(ruleName)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, configMap := range configMaps {
+		if desired[types.NamespacedName{Namespace: configMap.Namespace, Name: configMap.Name}] {
+			continue
+		}
+
+		namespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: configMap.Namespace}}
+		action, err := client.DeleteSyncedConfigMap(rules, namespace, configMap)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		summary.Add(action)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// OwnedConfigMaps returns all cached ConfigMaps synced by the ConfigMapSyncRule with the given name.
+func (client *Client) OwnedConfigMaps(ruleName string) (configMaps []*v1.ConfigMap, err error) {
+	objs, err := client.ConfigMapInformer.GetIndexer().ByIndex(ruleIndex, ruleName)
+	if err != nil {
+		configMapRuleNameLogger(ruleName).Errorf("failed to list owned configmaps: %s", err.Error())
+		return nil, err
+	}
+
+	for _, obj := range objs {
+		if configMap, ok := obj.(*v1.ConfigMap); ok {
+			configMaps = append(configMaps, configMap)
+		}
+	}
+
+	return
+}
+
+func (client *Client) CreateConfigMap(configMap *v1.ConfigMap) error {
+	logger := configMapLogger(configMap)
+	logger.Infof("creating configmap")
+
+	created, err := client.DefaultClientset.CoreV1().ConfigMaps(configMap.Namespace).Create(client.Context, configMap, metav1.CreateOptions{})
+	if err != nil {
+		logger.Errorf("failed to create configmap - %s", err.Error())
+		return err
+	}
+
+	client.verifiedCopies.record(created)
+	return nil
+}
+
+func (client *Client) UpdateConfigMap(configMap *v1.ConfigMap) error {
+	logger := configMapLogger(configMap)
+	logger.Infof("updating configmap")
+
+	updated, err := client.DefaultClientset.CoreV1().ConfigMaps(configMap.Namespace).Update(client.Context, configMap, metav1.UpdateOptions{})
+	if err != nil {
+		logger.Errorf("failed to update configmap - %s", err.Error())
+		return err
+	}
+
+	client.verifiedCopies.record(updated)
+	return nil
+}
+
+func (client *Client) DeleteConfigMap(namespace *v1.Namespace, configMap *v1.ConfigMap) error {
+	logger := configMapLogger(configMap, namespace)
+	logger.Infof("deleting configmap")
+
+	err := client.DefaultClientset.CoreV1().ConfigMaps(namespace.Name).Delete(client.Context, configMap.Name, metav1.DeleteOptions{})
+	if err != nil {
+		logger.Errorf("failed to delete configmap - %s", err.Error())
+	}
+
+	return err
+}
+
+func (client *Client) GetConfigMap(namespace, name string) (configMap *v1.ConfigMap, err error) {
+	configMap, err = client.DefaultClientset.CoreV1().ConfigMaps(namespace).Get(client.Context, name, metav1.GetOptions{})
+	if err != nil {
+		configMapNameLogger(namespace, name).Errorf("failed to get configmap: %s", err.Error())
+	}
+	return
+}
+
+// ConfigMapsAreEqual compares the content hashes of the given ConfigMaps, computed from their content rather than
+// taken from the hashes recorded on synced copies, which may be stale.
+func ConfigMapsAreEqual(a, b *v1.ConfigMap) bool {
+	return ConfigMapContentHash(a) == ConfigMapContentHash(b)
+}
+
+// ConfigMapContentHash returns a hash of the content of the given ConfigMap that is synced to its copies:
+// its data and the labels and annotations not set by kube-secret-sync.
+func ConfigMapContentHash(configMap *v1.ConfigMap) string {
+	return contentHash(struct {
+		Data        map[string]string `json:"data"`
+		BinaryData  map[string][]byte `json:"binaryData"`
+		Labels      map[string]string `json:"labels"`
+		Annotations map[string]string `json:"annotations"`
+	}{
+		Data:        configMap.Data,
+		BinaryData:  configMap.BinaryData,
+		Labels:      CopyLabels(configMap.Labels),
+		Annotations: CopyAnnotations(configMap.Annotations),
+	})
+}
+
+// ConfigMapIsUpToDate determines whether or not the given synced copy was written from the given prepared copy.
+func (client *Client) ConfigMapIsUpToDate(existing, prepared *v1.ConfigMap) bool {
+	hash := prepared.Annotations[constants.ContentHashAnnotationKey]
+	return client.CopyIsUpToDate(existing, hash, func() bool { return ConfigMapContentHash(existing) == hash })
+}
+
+// PrepareConfigMap builds the copy of the given source ConfigMap to write to the given Namespace.
+func PrepareConfigMap(rule *typesv1.ConfigMapSyncRule, namespace *v1.Namespace, configMap *v1.ConfigMap) *v1.ConfigMap {
+	prepared := &v1.ConfigMap{
+		TypeMeta: configMap.TypeMeta,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            configMap.Name,
+			Namespace:       namespace.Name,
+			Labels:          LabelRule(CopyLabels(configMap.Labels), clientset.ConfigMapSyncRule, rule),
+			Annotations:     AnnotateProvenance(Manage(CopyAnnotations(configMap.Annotations)), rule.Name, configMap),
+			OwnerReferences: []metav1.OwnerReference{ConfigMapOwnerReference(rule)},
+		},
+		Immutable:  configMap.Immutable,
+		Data:       TransformData(&rule.Spec.Rules.Keys, configMap.Data),
+		BinaryData: TransformData(&rule.Spec.Rules.Keys, configMap.BinaryData),
+	}
+	prepared.Annotations[constants.ContentHashAnnotationKey] = ConfigMapContentHash(prepared)
+
+	return prepared
+}
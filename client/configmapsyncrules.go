@@ -0,0 +1,157 @@
+package client
+
+import (
+	"fmt"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/constants"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/cache"
+)
+
+func (client *Client) ConfigMapSyncRuleEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    client.AddedConfigMapSyncRuleHandler,
+		UpdateFunc: client.ModifiedConfigMapSyncRuleHandler,
+		DeleteFunc: client.DeletedConfigMapSyncRuleHandler,
+	}
+}
+
+func (client *Client) AddedConfigMapSyncRuleHandler(obj interface{}) {
+	rule, ok := obj.(*typesv1.ConfigMapSyncRule)
+	if !ok {
+		log.Error("failed to cast ConfigMapSyncRule")
+		return
+	}
+
+	configMapRuleLogger(rule).Infof("added")
+	client.EnqueueConfigMapSyncRule(rule)
+}
+
+// ModifiedConfigMapSyncRuleHandler handles syncing configmaps after a ConfigMapSyncRule has been modified,
+// the same way ModifiedSecretSyncRuleHandler does for SecretSyncRules.
+func (client *Client) ModifiedConfigMapSyncRuleHandler(oldObj, newObj interface{}) {
+	rule, ok := newObj.(*typesv1.ConfigMapSyncRule)
+	if !ok {
+		log.Error("failed to cast ConfigMapSyncRule")
+		return
+	}
+
+	if old, ok := oldObj.(*typesv1.ConfigMapSyncRule); ok {
+		if old.ResourceVersion == rule.ResourceVersion {
+			configMapRuleLogger(rule).Debugf("periodic resync")
+			client.EnqueueConfigMapSyncRule(rule)
+			return
+		}
+
+		if old.Generation == rule.Generation && old.DeletionTimestamp.Equal(rule.DeletionTimestamp) {
+			configMapRuleLogger(rule).Debugf("status or metadata updated")
+			return
+		}
+	}
+
+	configMapRuleLogger(rule).Infof("modified")
+	client.EnqueueConfigMapSyncRule(rule)
+}
+
+func (client *Client) DeletedConfigMapSyncRuleHandler(obj interface{}) {
+	rule, ok := objectFromTombstone(obj).(*typesv1.ConfigMapSyncRule)
+	if !ok {
+		log.Error("failed to cast ConfigMapSyncRule")
+		return
+	}
+
+	configMapRuleLogger(rule).Infof("deleted")
+	client.EnqueueConfigMapSyncRule(rule)
+}
+
+// SyncConfigMapSyncRule reconciles the ConfigMapSyncRule with the given name against the informer caches.
+// Like SyncSecretSyncRule, it is safe to call repeatedly for the same name.
+func (client *Client) SyncConfigMapSyncRule(name string) error {
+	rule, err := client.ConfigMapSyncRuleLister.Get(name)
+	if errors.IsNotFound(err) {
+		logger := configMapRuleNameLogger(name)
+		logger.Debugf("no longer exists, removing synced configmaps")
+
+		summary := new(SyncSummary)
+		err := client.PruneOwnedConfigMaps(name, typesv1.ConfigMapRules{}, nil, summary)
+		summary.Log(logger)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if rule.DeletionTimestamp != nil {
+		return nil
+	}
+
+	logger := configMapRuleLogger(rule)
+	logger.Debugf("syncing")
+
+	summary := new(SyncSummary)
+	err = client.syncConfigMapSyncRule(rule, summary)
+	summary.Log(logger)
+
+	return utilerrors.NewAggregate([]error{err, client.UpdateConfigMapSyncRuleStatus(rule, summary)})
+}
+
+func (client *Client) syncConfigMapSyncRule(rule *typesv1.ConfigMapSyncRule, summary *SyncSummary) error {
+	logger := configMapRuleLogger(rule)
+
+	configMaps, missing, err := client.SourceConfigMaps(rule)
+	if err != nil {
+		return err
+	}
+
+	summary.Sources = len(configMaps)
+	for _, reference := range missing {
+		logger.Debugf("source configmap %s/%s does not exist", reference.Namespace, reference.Name)
+		summary.MissingSources = append(summary.MissingSources, reference.Namespace+"/"+reference.Name)
+	}
+
+	var errs []error
+	desired := make(map[types.NamespacedName]bool)
+
+	namespaces := rule.Namespaces(client.NamespaceLister)
+	summary.Targets = len(namespaces)
+	summary.Refused(rule.RefusedNamespaces(client.NamespaceLister))
+
+	for _, namespace := range namespaces {
+		for _, configMap := range configMaps {
+			desired[types.NamespacedName{Namespace: namespace.Name, Name: configMap.Name}] = true
+
+			action, err := client.SyncConfigMap(rule, namespace, configMap)
+			switch {
+			case err != nil:
+				errs = append(errs, err)
+				summary.Failed(namespace.Name, "", err)
+			case action == SyncActionSkipped:
+				summary.Failed(namespace.Name, ReasonConfigMapNotManaged, fmt.Errorf("%s: %w", configMap.Name, constants.ErrConfigMapNotManaged))
+			case action == SyncActionConflict:
+				summary.Conflicted(namespace.Name, configMap.Name, action)
+				summary.Failed(namespace.Name, ReasonConfigMapNotManaged, fmt.Errorf("%s: %w", configMap.Name, constants.ErrConfigMapNotManaged))
+			default:
+				summary.Succeeded(namespace.Name, action)
+			}
+		}
+	}
+
+	if err := client.PruneOwnedConfigMaps(rule.Name, rule.Spec.Rules, desired, summary); err != nil {
+		errs = append(errs, err)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (client *Client) ListConfigMapSyncRules() (rules []*typesv1.ConfigMapSyncRule, err error) {
+	rules, err = client.ConfigMapSyncRuleLister.List(labels.Everything())
+	if err != nil {
+		log.Errorf("failed to list ConfigMapSyncRules: %s", err.Error())
+	}
+	return
+}
@@ -0,0 +1,172 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/alehechka/kube-secret-sync/api/types"
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
+	pkg "github.com/alehechka/kube-secret-sync/client"
+	"github.com/alehechka/kube-secret-sync/constants"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_SyncConfigMapSyncRule(t *testing.T) {
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultConfigMap, testConfigMapSyncRule)
+
+	err := client.SyncConfigMapSyncRule(keyTestConfigMapSyncRule)
+	assert.NoError(t, err)
+
+	configMap, err := client.GetConfigMap(keyTestNamespace, keyDefaultConfigMap)
+	assert.NoError(t, err)
+	assert.True(t, pkg.IsManagedBy(configMap))
+	assert.True(t, pkg.ConfigMapsAreEqual(defaultConfigMap, configMap))
+
+	rule, err := client.KubeSecretSyncClientset.ConfigMapSyncRules().Get(client.Context, keyTestConfigMapSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, meta.IsStatusConditionTrue(rule.Status.Conditions, typesv1.ConditionReady))
+}
+
+func Test_SyncConfigMapSyncRule_NoRule(t *testing.T) {
+	copy := pkg.PrepareConfigMap(testConfigMapSyncRule, testNamespace, defaultConfigMap)
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultConfigMap, copy)
+
+	err := client.SyncConfigMapSyncRule(keyTestConfigMapSyncRule)
+	assert.NoError(t, err)
+
+	_, err = client.GetConfigMap(keyTestNamespace, keyDefaultConfigMap)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func Test_SyncConfigMapSyncRule_Unmanaged(t *testing.T) {
+	unmanaged := defaultConfigMap.DeepCopy()
+	unmanaged.Namespace = keyTestNamespace
+	unmanaged.Data = map[string]string{"ca.crt": "other"}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultConfigMap, unmanaged, testConfigMapSyncRule)
+
+	err := client.SyncConfigMapSyncRule(keyTestConfigMapSyncRule)
+	assert.NoError(t, err)
+
+	configMap, err := client.GetConfigMap(keyTestNamespace, keyDefaultConfigMap)
+	assert.NoError(t, err)
+	assert.Equal(t, "other", configMap.Data["ca.crt"])
+
+	rule, err := client.KubeSecretSyncClientset.ConfigMapSyncRules().Get(client.Context, keyTestConfigMapSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.ReasonConfigMapNotManaged, rule.Status.Failures[0].Reason)
+}
+
+func Test_SyncConfigMapSyncRule_SyncedByOtherRule(t *testing.T) {
+	other := testConfigMapSyncRule.DeepCopy()
+	other.Name = "other-configmap-sync-rule"
+
+	synced := pkg.PrepareConfigMap(other, testNamespace, defaultConfigMap)
+	synced.Data = map[string]string{"ca.crt": "other"}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultConfigMap, synced, testConfigMapSyncRule)
+
+	err := client.SyncConfigMapSyncRule(keyTestConfigMapSyncRule)
+	assert.NoError(t, err)
+
+	configMap, err := client.GetConfigMap(keyTestNamespace, keyDefaultConfigMap)
+	assert.NoError(t, err)
+	assert.Equal(t, "other", configMap.Data["ca.crt"])
+	assert.Equal(t, other.Name, configMap.OwnerReferences[0].Name)
+
+	rule, err := client.KubeSecretSyncClientset.ConfigMapSyncRules().Get(client.Context, keyTestConfigMapSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, meta.IsStatusConditionTrue(rule.Status.Conditions, typesv1.ConditionConflict))
+	assert.Equal(t, pkg.ReasonConfigMapNotManaged, rule.Status.Failures[0].Reason)
+	assert.Equal(t, []typesv1.NamespaceConflict{{Namespace: keyTestNamespace, Name: keyDefaultConfigMap, Resolution: "Failed"}}, rule.Status.Conflicts)
+}
+
+func Test_SyncConfigMapSyncRule_PrunesUntargeted(t *testing.T) {
+	excluded := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: keyExcludedNamespace}}
+
+	rule := testConfigMapSyncRule.DeepCopy()
+	rule.Spec.Rules.Namespaces.Exclude = types.StringSlice{keyExcludedNamespace}
+
+	stale := pkg.PrepareConfigMap(rule, excluded, defaultConfigMap)
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, excluded, defaultConfigMap, stale, rule)
+
+	err := client.SyncConfigMapSyncRule(keyTestConfigMapSyncRule)
+	assert.NoError(t, err)
+
+	_, err = client.GetConfigMap(keyTestNamespace, keyDefaultConfigMap)
+	assert.NoError(t, err)
+
+	_, err = client.GetConfigMap(keyExcludedNamespace, keyDefaultConfigMap)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func Test_SyncConfigMapSyncRule_KeepsOtherRuleKindCopies(t *testing.T) {
+	excluded := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: keyExcludedNamespace}}
+
+	rule := testConfigMapSyncRule.DeepCopy()
+	rule.Spec.Rules.Namespaces.Exclude = types.StringSlice{keyExcludedNamespace}
+
+	// a ResourceSyncRule with the same name as the ConfigMapSyncRule syncing ConfigMaps
+	resourceRule := &typesv1.ResourceSyncRule{ObjectMeta: metav1.ObjectMeta{Name: keyTestConfigMapSyncRule}}
+	other := pkg.PrepareConfigMap(rule, excluded, defaultConfigMap)
+	other.Labels[constants.RuleKindLabelKey] = clientset.ResourceSyncRule
+	other.OwnerReferences = []metav1.OwnerReference{pkg.ResourceOwnerReference(resourceRule)}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, excluded, defaultConfigMap, other, rule)
+
+	err := client.SyncConfigMapSyncRule(keyTestConfigMapSyncRule)
+	assert.NoError(t, err)
+
+	_, err = client.GetConfigMap(keyExcludedNamespace, keyDefaultConfigMap)
+	assert.NoError(t, err)
+}
+
+func Test_EnqueueDriftedConfigMap_Modified(t *testing.T) {
+	drifted := pkg.PrepareConfigMap(testConfigMapSyncRule, testNamespace, defaultConfigMap)
+	drifted.Data = map[string]string{"drifted": "value"}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultConfigMap, testConfigMapSyncRule)
+
+	client.EnqueueDriftedConfigMap(drifted, false)
+	assert.Equal(t, 1, client.Queue.Len())
+}
+
+func Test_EnqueueConfigMap(t *testing.T) {
+	client := InitializeTestClientset(testSecretSyncRule, testConfigMapSyncRule)
+
+	client.EnqueueConfigMap(defaultConfigMap, "modified")
+	assert.Equal(t, 1, client.Queue.Len())
+}
+
+func Test_PrepareConfigMap_Provenance(t *testing.T) {
+	source := defaultConfigMap.DeepCopy()
+	source.ResourceVersion = "42"
+
+	prepared := pkg.PrepareConfigMap(testConfigMapSyncRule, testNamespace, source)
+
+	assert.Equal(t, keyDefault, prepared.Annotations[constants.SourceNamespaceAnnotationKey])
+	assert.Equal(t, keyDefaultConfigMap, prepared.Annotations[constants.SourceNameAnnotationKey])
+	assert.Equal(t, "42", prepared.Annotations[constants.SourceResourceVersionAnnotationKey])
+	assert.Equal(t, keyTestConfigMapSyncRule, prepared.Annotations[constants.RuleAnnotationKey])
+	assert.Equal(t, pkg.ConfigMapContentHash(prepared), prepared.Annotations[constants.ContentHashAnnotationKey])
+	assert.Equal(t, pkg.ConfigMapContentHash(source), pkg.ConfigMapContentHash(prepared))
+}
+
+func Test_SyncConfigMap_SkipsUnchangedContent(t *testing.T) {
+	source := defaultConfigMap.DeepCopy()
+	source.ResourceVersion = "1"
+	synced := pkg.PrepareConfigMap(testConfigMapSyncRule, testNamespace, source)
+	source.ResourceVersion = "2"
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, source, synced, testConfigMapSyncRule)
+
+	action, err := client.SyncConfigMap(testConfigMapSyncRule, testNamespace, source)
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.SyncActionNone, action)
+}
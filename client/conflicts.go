@@ -0,0 +1,65 @@
+package client
+
+import (
+	"reflect"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+)
+
+// ResolveConflict decides how the given prepared copy takes the place of the given existing Secret that the rule does not manage,
+// according to the given conflict policy. It returns the action taken and the Secret to write, which is nil if nothing needs to be written.
+func ResolveConflict(policy typesv1.ConflictPolicy, prepared, existing *v1.Secret) (SyncAction, *v1.Secret) {
+	switch policy {
+	case typesv1.ConflictPolicySkip:
+		return SyncActionSkipped, nil
+	case typesv1.ConflictPolicyOverwrite:
+		return SyncActionOverwritten, prepared
+	case typesv1.ConflictPolicyAdopt:
+		if prepared.Type != existing.Type || !reflect.DeepEqual(prepared.Data, existing.Data) {
+			return SyncActionConflict, nil
+		}
+		return SyncActionAdopted, prepared
+	case typesv1.ConflictPolicyMerge:
+		return mergeMissingKeys(prepared, existing)
+	}
+
+	return SyncActionConflict, nil
+}
+
+// logConflict logs the action taken on an existing Secret that the rule does not manage.
+func logConflict(logger *log.Entry, policy typesv1.ConflictPolicy, action SyncAction) {
+	logger = logger.WithField("conflictPolicy", policy)
+
+	switch action {
+	case SyncActionNone:
+		logger.Debugf("existing secret is not managed and already has every synced key")
+	case SyncActionSkipped, SyncActionConflict:
+		logger.Debugf("existing secret is not managed and was left untouched (%s)", action)
+	default:
+		logger.Infof("existing secret is not managed and was %s", action)
+	}
+}
+
+// mergeMissingKeys adds the keys of the given prepared copy that are missing from the given existing Secret to a copy of it.
+// An existing Secret that already has every key is left as it is.
+func mergeMissingKeys(prepared, existing *v1.Secret) (SyncAction, *v1.Secret) {
+	merged := existing.DeepCopy()
+	if merged.Data == nil {
+		merged.Data = make(map[string][]byte)
+	}
+
+	added := false
+	for key, value := range prepared.Data {
+		if _, ok := merged.Data[key]; !ok {
+			merged.Data[key] = value
+			added = true
+		}
+	}
+
+	if !added {
+		return SyncActionNone, nil
+	}
+	return SyncActionMerged, merged
+}
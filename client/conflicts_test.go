@@ -0,0 +1,140 @@
+package client_test
+
+import (
+	"testing"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	pkg "github.com/alehechka/kube-secret-sync/client"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_ResolveConflict(t *testing.T) {
+	prepared := &v1.Secret{Data: map[string][]byte{"a": []byte("1"), "b": []byte("2")}}
+	matching := &v1.Secret{Data: map[string][]byte{"a": []byte("1"), "b": []byte("2")}}
+	differing := &v1.Secret{Data: map[string][]byte{"a": []byte("0")}}
+
+	action, resolved := pkg.ResolveConflict(typesv1.ConflictPolicySkip, prepared, differing)
+	assert.Equal(t, pkg.SyncActionSkipped, action)
+	assert.Nil(t, resolved)
+
+	action, resolved = pkg.ResolveConflict(typesv1.ConflictPolicyOverwrite, prepared, differing)
+	assert.Equal(t, pkg.SyncActionOverwritten, action)
+	assert.Same(t, prepared, resolved)
+
+	action, resolved = pkg.ResolveConflict(typesv1.ConflictPolicyAdopt, prepared, matching)
+	assert.Equal(t, pkg.SyncActionAdopted, action)
+	assert.Same(t, prepared, resolved)
+
+	action, resolved = pkg.ResolveConflict(typesv1.ConflictPolicyAdopt, prepared, differing)
+	assert.Equal(t, pkg.SyncActionConflict, action)
+	assert.Nil(t, resolved)
+
+	action, resolved = pkg.ResolveConflict(typesv1.ConflictPolicyMerge, prepared, differing)
+	assert.Equal(t, pkg.SyncActionMerged, action)
+	assert.Equal(t, map[string][]byte{"a": []byte("0"), "b": []byte("2")}, resolved.Data)
+	assert.Equal(t, map[string][]byte{"a": []byte("0")}, differing.Data)
+
+	action, resolved = pkg.ResolveConflict(typesv1.ConflictPolicyMerge, prepared, matching)
+	assert.Equal(t, pkg.SyncActionNone, action)
+	assert.Nil(t, resolved)
+
+	action, resolved = pkg.ResolveConflict(typesv1.ConflictPolicyFail, prepared, matching)
+	assert.Equal(t, pkg.SyncActionConflict, action)
+	assert.Nil(t, resolved)
+}
+
+func conflictingRule(policy typesv1.ConflictPolicy) (*typesv1.SecretSyncRule, *v1.Secret, *v1.Secret) {
+	source := defaultSecret.DeepCopy()
+	source.Data = map[string][]byte{"a": []byte("1"), "b": []byte("2")}
+
+	rule := testSecretSyncRule.DeepCopy()
+	rule.Spec.Rules.ConflictPolicy = policy
+
+	unmanaged := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: keyDefaultSecret, Namespace: keyTestNamespace},
+		Data:       map[string][]byte{"a": []byte("0")},
+	}
+
+	return rule, source, unmanaged
+}
+
+func Test_SyncSecretSyncRule_ConflictPolicyMerge(t *testing.T) {
+	rule, source, unmanaged := conflictingRule(typesv1.ConflictPolicyMerge)
+	client := InitializeTestClientset(defaultNamespace, testNamespace, source, unmanaged, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err := client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.False(t, pkg.IsManagedBy(secret))
+	assert.Equal(t, map[string][]byte{"a": []byte("0"), "b": []byte("2")}, secret.Data)
+
+	rule, err = client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, meta.IsStatusConditionTrue(rule.Status.Conditions, typesv1.ConditionReady))
+	assert.Equal(t, []typesv1.NamespaceConflict{{Namespace: keyTestNamespace, Name: keyDefaultSecret, Resolution: "Merged"}}, rule.Status.Conflicts)
+}
+
+func Test_SyncSecretSyncRule_ConflictPolicyAdopt(t *testing.T) {
+	rule, source, unmanaged := conflictingRule(typesv1.ConflictPolicyAdopt)
+	client := InitializeTestClientset(defaultNamespace, testNamespace, source, unmanaged, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err := client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.False(t, pkg.IsManagedBy(secret))
+
+	rule, err = client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, meta.IsStatusConditionTrue(rule.Status.Conditions, typesv1.ConditionConflict))
+
+	rule, source, unmanaged = conflictingRule(typesv1.ConflictPolicyAdopt)
+	unmanaged.Data = source.Data
+	client = InitializeTestClientset(defaultNamespace, testNamespace, source, unmanaged, rule)
+
+	err = client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err = client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.True(t, pkg.IsManagedBy(secret))
+}
+
+func Test_SyncSecretSyncRule_ConflictPolicySkip(t *testing.T) {
+	rule, source, unmanaged := conflictingRule(typesv1.ConflictPolicySkip)
+	client := InitializeTestClientset(defaultNamespace, testNamespace, source, unmanaged, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err := client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.Equal(t, unmanaged.Data, secret.Data)
+
+	rule, err = client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, meta.IsStatusConditionTrue(rule.Status.Conditions, typesv1.ConditionReady))
+	assert.False(t, meta.IsStatusConditionTrue(rule.Status.Conditions, typesv1.ConditionConflict))
+	assert.Equal(t, "Skipped", rule.Status.Conflicts[0].Resolution)
+}
+
+func Test_SyncSecretSyncRule_ConflictPolicyFail(t *testing.T) {
+	rule, source, unmanaged := conflictingRule(typesv1.ConflictPolicyFail)
+	client := InitializeTestClientset(defaultNamespace, testNamespace, source, unmanaged, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	rule, err = client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.False(t, meta.IsStatusConditionTrue(rule.Status.Conditions, typesv1.ConditionReady))
+	assert.True(t, meta.IsStatusConditionTrue(rule.Status.Conditions, typesv1.ConditionConflict))
+	assert.Equal(t, "Failed", rule.Status.Conflicts[0].Resolution)
+	assert.Equal(t, pkg.ReasonSecretNotManaged, rule.Status.Failures[0].Reason)
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"sort"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+)
+
+// TransformData filters and renames the keys of the given Secret data according to the given KeyRules.
+// Renamed keys are written after all other keys in sorted order, so a renamed key always replaces a synced key with the same name.
+func TransformData[V any](keys *typesv1.KeyRules, data map[string]V) map[string]V {
+	if len(data) == 0 {
+		return data
+	}
+
+	sorted := make([]string, 0, len(data))
+	for key := range data {
+		if keys.ShouldSyncKey(key) {
+			sorted = append(sorted, key)
+		}
+	}
+	sort.Strings(sorted)
+
+	transformed := make(map[string]V, len(sorted))
+	var renamed []string
+	for _, key := range sorted {
+		if keys.TargetKey(key) != key {
+			renamed = append(renamed, key)
+			continue
+		}
+		transformed[key] = data[key]
+	}
+	for _, key := range renamed {
+		transformed[keys.TargetKey(key)] = data[key]
+	}
+
+	if len(transformed) == 0 {
+		return nil
+	}
+
+	return transformed
+}
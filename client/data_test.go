@@ -5,6 +5,8 @@ import (
 	"github.com/alehechka/kube-secret-sync/constants"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 const (
@@ -12,6 +14,20 @@ const (
 	keyTestSecret    string = "test-secret"
 	keyDefault       string = "default"
 	keyDefaultSecret string = "default-secret"
+
+	keyExcludedNamespace  string = "excluded-namespace"
+	keyTestSecretSyncRule string = "test-secret-sync-rule"
+
+	keyDefaultConfigMap      string = "default-configmap"
+	keyTestConfigMapSyncRule string = "test-configmap-sync-rule"
+
+	keyDefaultNetworkPolicy string = "default-network-policy"
+	keyTestResourceSyncRule string = "test-resource-sync-rule"
+
+	keyTestSecretSyncPolicy string = "test-secret-sync-policy"
+
+	keyRemoteCluster    string = "remote-cluster"
+	keyRemoteKubeconfig string = "remote-kubeconfig"
 )
 
 var managedByAnnotations = map[string]string{constants.ManagedByAnnotationKey: constants.ManagedByAnnotationValue}
@@ -20,4 +36,34 @@ var testNamespace = &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: keyTestNam
 var defaultNamespace = &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: keyDefault}}
 var defaultSecret = &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: keyDefaultSecret, Namespace: keyDefault}}
 var testSecret = &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: keyTestSecret, Namespace: keyTestSecret}}
-var testSecretSyncRule = &typesv1.SecretSyncRule{Spec: typesv1.SecretSyncRuleSpec{Secret: typesv1.Secret{Name: keyDefaultSecret, Namespace: keyDefault}}}
+var testSecretSyncRule = &typesv1.SecretSyncRule{ObjectMeta: metav1.ObjectMeta{Name: keyTestSecretSyncRule}, Spec: typesv1.SecretSyncRuleSpec{Secret: typesv1.Secret{Name: keyDefaultSecret, Namespace: keyDefault}}}
+var defaultConfigMap = &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: keyDefaultConfigMap, Namespace: keyDefault}, Data: map[string]string{"ca.crt": "ca"}}
+var testConfigMapSyncRule = &typesv1.ConfigMapSyncRule{ObjectMeta: metav1.ObjectMeta{Name: keyTestConfigMapSyncRule}, Spec: typesv1.ConfigMapSyncRuleSpec{ConfigMap: typesv1.ConfigMap{Name: keyDefaultConfigMap, Namespace: keyDefault}}}
+
+var networkPolicyKind = schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"}
+var networkPolicyResource = networkPolicyKind.GroupVersion().WithResource("networkpolicies")
+var clusterRoleKind = schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}
+var clusterRoleResource = clusterRoleKind.GroupVersion().WithResource("clusterroles")
+
+var defaultNetworkPolicy = &unstructured.Unstructured{Object: map[string]interface{}{
+	"apiVersion": "networking.k8s.io/v1",
+	"kind":       "NetworkPolicy",
+	"metadata": map[string]interface{}{
+		"name":            keyDefaultNetworkPolicy,
+		"namespace":       keyDefault,
+		"uid":             "3c0d4a5e-2b1f-4f4e-9a57-0b6f1a2c3d4e",
+		"resourceVersion": "42",
+	},
+	"spec": map[string]interface{}{
+		"podSelector": map[string]interface{}{},
+		"policyTypes": []interface{}{"Ingress"},
+	},
+	"status": map[string]interface{}{"conditions": []interface{}{}},
+}}
+var testResourceSyncRule = &typesv1.ResourceSyncRule{ObjectMeta: metav1.ObjectMeta{Name: keyTestResourceSyncRule}, Spec: typesv1.ResourceSyncRuleSpec{Resource: typesv1.Resource{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy", Name: keyDefaultNetworkPolicy, Namespace: keyDefault}}}
+
+var testNamespacedSecretSyncRule = &typesv1.NamespacedSecretSyncRule{ObjectMeta: metav1.ObjectMeta{Name: keyTestSecretSyncRule, Namespace: keyDefault}, Spec: typesv1.SecretSyncRuleSpec{Secret: typesv1.Secret{Name: keyDefaultSecret}}}
+var testSecretSyncPolicy = &typesv1.SecretSyncPolicy{ObjectMeta: metav1.ObjectMeta{Name: keyTestSecretSyncPolicy}, Spec: typesv1.SecretSyncPolicySpec{Sources: typesv1.NamespaceRules{Include: []string{keyDefault}}, Targets: typesv1.NamespaceRules{Include: []string{keyTestNamespace}}}}
+
+var remoteKubeconfig = &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: keyRemoteKubeconfig, Namespace: keyDefault}, Data: map[string][]byte{typesv1.DefaultKubeconfigKey: []byte(keyRemoteCluster)}}
+var testClusterSecretSyncRule = &typesv1.SecretSyncRule{ObjectMeta: metav1.ObjectMeta{Name: keyTestSecretSyncRule}, Spec: typesv1.SecretSyncRuleSpec{Secret: typesv1.Secret{Name: keyDefaultSecret, Namespace: keyDefault}, Clusters: []typesv1.Cluster{{Name: keyRemoteCluster, Secret: typesv1.Secret{Name: keyRemoteKubeconfig, Namespace: keyDefault}}}}}
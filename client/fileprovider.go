@@ -0,0 +1,253 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alehechka/kube-secret-sync/constants"
+)
+
+// FileProviderName is the name that external sources reference the FileProvider by.
+const FileProviderName = "file"
+
+// defaultFilePollInterval is the interval at which a FileProvider checks its files for changes if none is configured.
+const defaultFilePollInterval = 10 * time.Second
+
+// maxFileDepth bounds how deep a FileProvider descends into nested and symlinked directories.
+const maxFileDepth = 8
+
+// FileProvider is the SourceProvider reading data from files below a root directory, such as a volume mounted into the controller.
+//
+// A path referencing a directory yields one key per file in it, named after the file.
+// A path referencing a file ending in .env yields one key per KEY=value line, a file ending in .json yields one key per top level field,
+// and any other file yields a single key named after the file.
+// Hidden files are ignored, which skips the bookkeeping entries of mounted ConfigMap and Secret volumes.
+type FileProvider struct {
+	Root         string
+	PollInterval time.Duration
+}
+
+// NewFileProvider creates a FileProvider reading files below the given root directory.
+func NewFileProvider(root string, pollInterval time.Duration) *FileProvider {
+	if pollInterval <= 0 {
+		pollInterval = defaultFilePollInterval
+	}
+
+	return &FileProvider{Root: root, PollInterval: pollInterval}
+}
+
+func (provider *FileProvider) Name() string {
+	return FileProviderName
+}
+
+// Data reads the data of the file or directory at the given path below the root directory.
+// Paths cannot leave the root directory, neither through .. elements nor through symlinks.
+func (provider *FileProvider) Data(path string) (map[string][]byte, error) {
+	resolved, err := provider.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return nil, provider.readError(path, err)
+	}
+
+	if !info.IsDir() {
+		content, err := os.ReadFile(resolved)
+		if err != nil {
+			return nil, provider.readError(path, err)
+		}
+
+		data, err := parseDataFile(filepath.Base(filepath.Clean("/"+path)), content)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%s: %w", FileProviderName, path, err)
+		}
+		return data, nil
+	}
+
+	entries, err := os.ReadDir(resolved)
+	if err != nil {
+		return nil, provider.readError(path, err)
+	}
+
+	data := make(map[string][]byte)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		info, err := os.Stat(filepath.Join(resolved, entry.Name()))
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		value, err := os.ReadFile(filepath.Join(resolved, entry.Name()))
+		if err != nil {
+			return nil, provider.readError(path, err)
+		}
+		data[entry.Name()] = value
+	}
+
+	return data, nil
+}
+
+// Watch polls the files below the root directory and calls changed with the path of every file that was added, modified or removed.
+func (provider *FileProvider) Watch(ctx context.Context, changed func(path string)) error {
+	ticker := time.NewTicker(provider.PollInterval)
+	defer ticker.Stop()
+
+	checksums := provider.checksums()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current := provider.checksums()
+		for path, checksum := range current {
+			if checksums[path] != checksum {
+				changed(path)
+			}
+		}
+		for path := range checksums {
+			if _, ok := current[path]; !ok {
+				changed(path)
+			}
+		}
+		checksums = current
+	}
+}
+
+// checksums returns the checksum of every file below the root directory by its slash separated path.
+func (provider *FileProvider) checksums() map[string][sha256.Size]byte {
+	checksums := make(map[string][sha256.Size]byte)
+
+	var walk func(dir, relative string, depth int)
+	walk = func(dir, relative string, depth int) {
+		entries, err := os.ReadDir(dir)
+		if err != nil || depth > maxFileDepth {
+			return
+		}
+
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+
+			name := filepath.Join(dir, entry.Name())
+			path := strings.TrimPrefix(relative+"/"+entry.Name(), "/")
+
+			info, err := os.Stat(name)
+			switch {
+			case err != nil:
+				continue
+			case info.IsDir():
+				walk(name, path, depth+1)
+			case info.Mode().IsRegular():
+				if content, err := os.ReadFile(name); err == nil {
+					checksums[path] = sha256.Sum256(content)
+				}
+			}
+		}
+	}
+	walk(provider.Root, "", 0)
+
+	return checksums
+}
+
+// resolve returns the file path of the given path below the root directory, following symlinks.
+func (provider *FileProvider) resolve(path string) (string, error) {
+	root, err := filepath.EvalSymlinks(provider.Root)
+	if err != nil {
+		return "", provider.readError(path, err)
+	}
+
+	resolved, err := filepath.EvalSymlinks(filepath.Join(root, filepath.Clean("/"+path)))
+	if err != nil {
+		return "", provider.readError(path, err)
+	}
+
+	if relative, err := filepath.Rel(root, resolved); err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s:%s: %w: path leaves the root directory", FileProviderName, path, constants.ErrInvalidSource)
+	}
+
+	return resolved, nil
+}
+
+func (provider *FileProvider) readError(path string, err error) error {
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s:%s: %w", FileProviderName, path, constants.ErrSourceNotFound)
+	}
+
+	return fmt.Errorf("%s:%s: %w: %s", FileProviderName, path, constants.ErrInvalidSource, err.Error())
+}
+
+// parseDataFile parses the content of the file with the given name according to its extension.
+func parseDataFile(name string, content []byte) (map[string][]byte, error) {
+	switch filepath.Ext(name) {
+	case ".env":
+		return parseEnvFile(content)
+	case ".json":
+		return parseJSONFile(content)
+	}
+
+	return map[string][]byte{name: content}, nil
+}
+
+// parseEnvFile parses KEY=value lines, ignoring empty lines, comments and export prefixes and unquoting quoted values.
+func parseEnvFile(content []byte) (map[string][]byte, error) {
+	data := make(map[string][]byte)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%w: line %d is not a KEY=value pair", constants.ErrInvalidSource, line)
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+
+		data[key] = []byte(value)
+	}
+
+	return data, scanner.Err()
+}
+
+// parseJSONFile parses a JSON object, keeping string fields as they are and encoding any other field as JSON.
+func parseJSONFile(content []byte) (map[string][]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, fmt.Errorf("%w: %s", constants.ErrInvalidSource, err.Error())
+	}
+
+	data := make(map[string][]byte, len(fields))
+	for key, raw := range fields {
+		var value string
+		if err := json.Unmarshal(raw, &value); err == nil {
+			data[key] = []byte(value)
+			continue
+		}
+		data[key] = []byte(raw)
+	}
+
+	return data, nil
+}
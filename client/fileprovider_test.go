@@ -0,0 +1,101 @@
+package client_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pkg "github.com/alehechka/kube-secret-sync/client"
+	"github.com/alehechka/kube-secret-sync/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeSourceFiles writes the given files, keyed by their slash separated path, below a temporary directory and returns it.
+func writeSourceFiles(t *testing.T, files map[string]string) string {
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	return root
+}
+
+func Test_FileProvider_Data(t *testing.T) {
+	root := writeSourceFiles(t, map[string]string{
+		"database.env":       "# database\nexport USER=app\nPASSWORD=\"s3cr=t\"\n\n",
+		"api.json":           `{"token": "abc", "scopes": ["read"]}`,
+		"tls/tls.crt":        "cert",
+		"tls/tls.key":        "key",
+		"tls/..data/ignored": "hidden",
+		"ca.crt":             "ca",
+	})
+	provider := pkg.NewFileProvider(root, 0)
+
+	data, err := provider.Data("database.env")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"USER": []byte("app"), "PASSWORD": []byte("s3cr=t")}, data)
+
+	data, err = provider.Data("/api.json")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"token": []byte("abc"), "scopes": []byte(`["read"]`)}, data)
+
+	data, err = provider.Data("tls")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")}, data)
+
+	data, err = provider.Data("ca.crt")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"ca.crt": []byte("ca")}, data)
+}
+
+func Test_FileProvider_Errors(t *testing.T) {
+	outside := writeSourceFiles(t, map[string]string{"secret": "outside"})
+	root := writeSourceFiles(t, map[string]string{"invalid.env": "not a pair", "invalid.json": "[]"})
+	assert.NoError(t, os.Symlink(outside, filepath.Join(root, "escape")))
+	provider := pkg.NewFileProvider(root, 0)
+
+	_, err := provider.Data("missing.env")
+	assert.ErrorIs(t, err, constants.ErrSourceNotFound)
+
+	_, err = provider.Data("invalid.env")
+	assert.ErrorIs(t, err, constants.ErrInvalidSource)
+
+	_, err = provider.Data("invalid.json")
+	assert.ErrorIs(t, err, constants.ErrInvalidSource)
+
+	_, err = provider.Data("escape/secret")
+	assert.ErrorIs(t, err, constants.ErrInvalidSource)
+
+	data, err := provider.Data("../..")
+	assert.NoError(t, err)
+	assert.Contains(t, data, "invalid.env")
+}
+
+func Test_FileProvider_Watch(t *testing.T) {
+	root := writeSourceFiles(t, map[string]string{"database.env": "USER=app", "removed": "x"})
+	provider := pkg.NewFileProvider(root, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan string, 10)
+	go func() { _ = provider.Watch(ctx, func(path string) { changed <- path }) }()
+	time.Sleep(50 * time.Millisecond)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "database.env"), []byte("USER=admin"), 0o600))
+	assert.NoError(t, os.Remove(filepath.Join(root, "removed")))
+
+	var paths []string
+	for len(paths) < 2 {
+		select {
+		case path := <-changed:
+			paths = append(paths, path)
+		case <-time.After(time.Second):
+			t.Fatalf("changes were not detected, got %v", paths)
+		}
+	}
+	assert.ElementsMatch(t, []string{"database.env", "removed"}, paths)
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// HasFinalizer checks whether the given object carries the kube-secret-sync finalizer.
+func HasFinalizer(obj metav1.Object) bool {
+	for _, finalizer := range obj.GetFinalizers() {
+		if finalizer == constants.FinalizerName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withoutFinalizer returns the given finalizers without the kube-secret-sync finalizer.
+func withoutFinalizer(finalizers []string) (remaining []string) {
+	for _, finalizer := range finalizers {
+		if finalizer != constants.FinalizerName {
+			remaining = append(remaining, finalizer)
+		}
+	}
+
+	return
+}
+
+// AddSecretSyncRuleFinalizer adds the kube-secret-sync finalizer to the given SecretSyncRule and returns the updated rule.
+func (client *Client) AddSecretSyncRuleFinalizer(rule *typesv1.SecretSyncRule) (*typesv1.SecretSyncRule, error) {
+	updated := rule.DeepCopy()
+	updated.Finalizers = append(updated.Finalizers, constants.FinalizerName)
+
+	updated, err := client.KubeSecretSyncClientset.SecretSyncRules().Update(client.Context, updated, metav1.UpdateOptions{})
+	if err != nil {
+		ruleLogger(rule).Errorf("failed to add finalizer: %s", err.Error())
+	}
+
+	return updated, err
+}
+
+// RemoveSecretSyncRuleFinalizer removes the kube-secret-sync finalizer from the given SecretSyncRule.
+func (client *Client) RemoveSecretSyncRuleFinalizer(rule *typesv1.SecretSyncRule) error {
+	updated := rule.DeepCopy()
+	updated.Finalizers = withoutFinalizer(updated.Finalizers)
+
+	_, err := client.KubeSecretSyncClientset.SecretSyncRules().Update(client.Context, updated, metav1.UpdateOptions{})
+	if err != nil {
+		ruleLogger(rule).Errorf("failed to remove finalizer: %s", err.Error())
+	}
+
+	return err
+}
+
+// AddNamespacedSecretSyncRuleFinalizer adds the kube-secret-sync finalizer to the given NamespacedSecretSyncRule and returns the updated rule.
+func (client *Client) AddNamespacedSecretSyncRuleFinalizer(rule *typesv1.NamespacedSecretSyncRule) (*typesv1.NamespacedSecretSyncRule, error) {
+	updated := rule.DeepCopy()
+	updated.Finalizers = append(updated.Finalizers, constants.FinalizerName)
+
+	updated, err := client.KubeSecretSyncClientset.NamespacedSecretSyncRules(rule.Namespace).Update(client.Context, updated, metav1.UpdateOptions{})
+	if err != nil {
+		namespacedRuleLogger(rule).Errorf("failed to add finalizer: %s", err.Error())
+	}
+
+	return updated, err
+}
+
+// RemoveNamespacedSecretSyncRuleFinalizer removes the kube-secret-sync finalizer from the given NamespacedSecretSyncRule.
+func (client *Client) RemoveNamespacedSecretSyncRuleFinalizer(rule *typesv1.NamespacedSecretSyncRule) error {
+	updated := rule.DeepCopy()
+	updated.Finalizers = withoutFinalizer(updated.Finalizers)
+
+	_, err := client.KubeSecretSyncClientset.NamespacedSecretSyncRules(rule.Namespace).Update(client.Context, updated, metav1.UpdateOptions{})
+	if err != nil {
+		namespacedRuleLogger(rule).Errorf("failed to remove finalizer: %s", err.Error())
+	}
+
+	return err
+}
+
+// FinalizeSecretSyncRule cleans up the copies of the given deleted SecretSyncRule and removes its finalizer once that succeeded.
+func (client *Client) FinalizeSecretSyncRule(rule *typesv1.SecretSyncRule) error {
+	if !HasFinalizer(rule) {
+		return nil
+	}
+
+	logger := ruleLogger(rule)
+	logger.Infof("deleted, removing synced secrets")
+
+	summary := new(SyncSummary)
+	err := client.cleanupSecretSyncRule(rule, summary)
+	summary.Log(logger)
+	if err != nil {
+		logger.Errorf("failed to remove synced secrets, keeping finalizer: %s", err.Error())
+		return err
+	}
+
+	return client.RemoveSecretSyncRuleFinalizer(rule)
+}
+
+// FinalizeNamespacedSecretSyncRule cleans up the copies of the given deleted NamespacedSecretSyncRule and removes its finalizer once that succeeded.
+// A rule that cannot be converted any more only has its local copies cleaned up.
+func (client *Client) FinalizeNamespacedSecretSyncRule(rule *typesv1.NamespacedSecretSyncRule) error {
+	if !HasFinalizer(rule) {
+		return nil
+	}
+
+	logger := namespacedRuleLogger(rule)
+	logger.Infof("deleted, removing synced secrets")
+
+	converted, err := client.ConvertNamespacedSecretSyncRule(rule)
+	if err != nil {
+		converted = &typesv1.SecretSyncRule{ObjectMeta: rule.ObjectMeta, Spec: typesv1.SecretSyncRuleSpec{DeletionPolicy: rule.Spec.DeletionPolicy}}
+	}
+
+	summary := new(SyncSummary)
+	err = client.cleanupSecretSyncRule(converted, summary)
+	summary.Log(logger)
+	if err != nil {
+		logger.Errorf("failed to remove synced secrets, keeping finalizer: %s", err.Error())
+		return err
+	}
+
+	return client.RemoveNamespacedSecretSyncRuleFinalizer(rule)
+}
+
+// cleanupSecretSyncRule applies the rule deletion policy of the given SecretSyncRule to all of its copies,
+// including those in remote clusters and in removed clusters that have not been pruned yet.
+// Remote clusters whose kubeconfig is missing or invalid are skipped, since they would otherwise block the deletion of the rule forever.
+func (client *Client) cleanupSecretSyncRule(rule *typesv1.SecretSyncRule, summary *SyncSummary) error {
+	policy := func(*v1.Secret) typesv1.DeletionPolicyType {
+		return rule.Spec.RuleDeletionPolicy()
+	}
+
+	var errs []error
+	if err := client.PruneOwnedSecrets(ruleKey(rule), rule.Spec.Rules, nil, policy, summary); err != nil {
+		errs = append(errs, err)
+	}
+
+	clusters := append(append([]typesv1.Cluster(nil), rule.Spec.Clusters...), RemovedClusters(rule)...)
+	for _, cluster := range clusters {
+		remote, err := client.RemoteClientset(cluster)
+		if IsRuleError(err) {
+			clusterLogger(rule, cluster.Name).Warnf("skipping cleanup: %s", err.Error())
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if err := client.PruneRemoteSecrets(remote, rule, nil, policy, &summary.Cluster(cluster).SyncSummary); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
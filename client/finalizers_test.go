@@ -0,0 +1,115 @@
+package client_test
+
+import (
+	"testing"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	pkg "github.com/alehechka/kube-secret-sync/client"
+	"github.com/alehechka/kube-secret-sync/constants"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func deletedRule(rule *typesv1.SecretSyncRule) *typesv1.SecretSyncRule {
+	deleted := rule.DeepCopy()
+	deleted.DeletionTimestamp = &metav1.Time{}
+	deleted.Finalizers = []string{constants.FinalizerName}
+	return deleted
+}
+
+func Test_SyncSecretSyncRule_AddsFinalizer(t *testing.T) {
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, testSecretSyncRule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	rule, err := client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, pkg.HasFinalizer(rule))
+}
+
+func Test_SyncSecretSyncRule_Finalize(t *testing.T) {
+	rule := deletedRule(testSecretSyncRule)
+	synced := PrepareSecret(rule, testNamespace, defaultSecret)
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, synced, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	_, err = client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.True(t, errors.IsNotFound(err))
+
+	rule, err = client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.False(t, pkg.HasFinalizer(rule))
+}
+
+func Test_SyncSecretSyncRule_FinalizeOrphan(t *testing.T) {
+	rule := deletedRule(testSecretSyncRule)
+	rule.Spec.DeletionPolicy = &typesv1.DeletionPolicy{OnRuleDeletion: typesv1.DeletionPolicyOrphan}
+	synced := PrepareSecret(rule, testNamespace, defaultSecret)
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, synced, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err := client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.False(t, pkg.IsManagedBy(secret))
+}
+
+func Test_SyncSecretSyncRule_FinalizeRemoteCluster(t *testing.T) {
+	rule := deletedRule(testClusterSecretSyncRule)
+
+	remoteSecret := PrepareSecret(rule, testNamespace, defaultSecret)
+	remoteSecret.OwnerReferences = nil
+
+	remote := fake.NewSimpleClientset(testNamespace, remoteSecret)
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, remoteKubeconfig, rule)
+	WithRemoteClusters(client, map[string]kubernetes.Interface{keyRemoteCluster: remote})
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	_, err = remote.CoreV1().Secrets(keyTestNamespace).Get(client.Context, keyDefaultSecret, metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func Test_SyncSecretSyncRule_FinalizeUnreachableCluster(t *testing.T) {
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, deletedRule(testClusterSecretSyncRule))
+	WithRemoteClusters(client, map[string]kubernetes.Interface{})
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	rule, err := client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.False(t, pkg.HasFinalizer(rule))
+}
+
+func Test_SyncNamespacedSecretSyncRule_Finalize(t *testing.T) {
+	rule := testNamespacedSecretSyncRule.DeepCopy()
+	rule.DeletionTimestamp = &metav1.Time{}
+	rule.Finalizers = []string{constants.FinalizerName}
+
+	converted, err := rule.SecretSyncRule(defaultNamespace, nil)
+	assert.NoError(t, err)
+	synced := PrepareSecret(converted, testNamespace, defaultSecret)
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, synced, rule)
+
+	err = client.SyncSecretSyncRule(keyDefault + "/" + keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	_, err = client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.True(t, errors.IsNotFound(err))
+
+	rule, err = client.KubeSecretSyncClientset.NamespacedSecretSyncRules(keyDefault).Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.False(t, pkg.HasFinalizer(rule))
+}
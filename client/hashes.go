@@ -0,0 +1,76 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/alehechka/kube-secret-sync/constants"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// contentHash returns the hex encoded SHA-256 hash of the JSON encoding of the given content.
+func contentHash(content interface{}) string {
+	encoded, _ := json.Marshal(content)
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
+}
+
+// verifiedCopies remembers the resourceVersion of every synced copy whose recorded content hash is known to match its content,
+// either because the controller wrote that version or because it already compared it.
+type verifiedCopies struct {
+	lock     sync.Mutex
+	versions map[types.UID]string
+}
+
+func (copies *verifiedCopies) has(obj metav1.Object) bool {
+	copies.lock.Lock()
+	defer copies.lock.Unlock()
+
+	version, ok := copies.versions[obj.GetUID()]
+	return ok && obj.GetUID() != "" && version == obj.GetResourceVersion()
+}
+
+func (copies *verifiedCopies) record(obj metav1.Object) {
+	if obj.GetUID() == "" || obj.GetResourceVersion() == "" {
+		return
+	}
+
+	copies.lock.Lock()
+	defer copies.lock.Unlock()
+
+	if copies.versions == nil {
+		copies.versions = make(map[types.UID]string)
+	}
+	copies.versions[obj.GetUID()] = obj.GetResourceVersion()
+}
+
+func (copies *verifiedCopies) forget(obj metav1.Object) {
+	copies.lock.Lock()
+	defer copies.lock.Unlock()
+
+	delete(copies.versions, obj.GetUID())
+}
+
+// CopyIsUpToDate determines whether or not the given synced copy records the given content hash of its prepared copy.
+// The recorded hash is trusted for versions of the copy that the controller wrote or already compared. Other versions,
+// such as copies edited by someone else, are compared with the given function once, so that drifted copies are still repaired.
+// Copies without a recorded hash are never up to date, so that they are rewritten with their provenance.
+func (client *Client) CopyIsUpToDate(existing metav1.Object, hash string, equal func() bool) bool {
+	if existing.GetAnnotations()[constants.ContentHashAnnotationKey] != hash {
+		return false
+	}
+
+	if client.verifiedCopies.has(existing) {
+		return true
+	}
+
+	if !equal() {
+		return false
+	}
+
+	client.verifiedCopies.record(existing)
+	return true
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	kssclientset "github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
+	"github.com/alehechka/kube-secret-sync/constants"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ruleIndex is the name of the Secret and ConfigMap informer index that maps synced copies to their rule.
+const ruleIndex = "rule"
+
+func (client *Client) InitializeInformers() error {
+	client.InformerFactory = informers.NewSharedInformerFactory(client.DefaultClientset, 0)
+
+	secrets := client.InformerFactory.Core().V1().Secrets()
+	client.SecretInformer = secrets.Informer()
+	client.SecretLister = secrets.Lister()
+	if err := client.SecretInformer.AddIndexers(cache.Indexers{ruleIndex: ruleIndexFunc}); err != nil {
+		return err
+	}
+
+	configMaps := client.InformerFactory.Core().V1().ConfigMaps()
+	client.ConfigMapInformer = configMaps.Informer()
+	client.ConfigMapLister = configMaps.Lister()
+	if err := client.ConfigMapInformer.AddIndexers(cache.Indexers{ruleIndex: ruleIndexFunc}); err != nil {
+		return err
+	}
+
+	namespaces := client.InformerFactory.Core().V1().Namespaces()
+	client.NamespaceInformer = namespaces.Informer()
+	client.NamespaceLister = namespaces.Lister()
+
+	client.SecretSyncRuleInformer = kssclientset.NewSecretSyncRuleInformer(client.KubeSecretSyncClientset, client.SyncConfig.ResyncInterval)
+	client.SecretSyncRuleLister = kssclientset.NewSecretSyncRuleLister(client.SecretSyncRuleInformer.GetIndexer())
+
+	client.ConfigMapSyncRuleInformer = kssclientset.NewConfigMapSyncRuleInformer(client.KubeSecretSyncClientset, client.SyncConfig.ResyncInterval)
+	client.ConfigMapSyncRuleLister = kssclientset.NewConfigMapSyncRuleLister(client.ConfigMapSyncRuleInformer.GetIndexer())
+
+	client.ResourceSyncRuleInformer = kssclientset.NewResourceSyncRuleInformer(client.KubeSecretSyncClientset, client.SyncConfig.ResyncInterval)
+	client.ResourceSyncRuleLister = kssclientset.NewResourceSyncRuleLister(client.ResourceSyncRuleInformer.GetIndexer())
+
+	client.NamespacedSecretSyncRuleInformer = kssclientset.NewNamespacedSecretSyncRuleInformer(client.KubeSecretSyncClientset, client.SyncConfig.ResyncInterval)
+	client.NamespacedSecretSyncRuleLister = kssclientset.NewNamespacedSecretSyncRuleLister(client.NamespacedSecretSyncRuleInformer.GetIndexer())
+
+	client.SecretSyncPolicyInformer = kssclientset.NewSecretSyncPolicyInformer(client.KubeSecretSyncClientset, client.SyncConfig.ResyncInterval)
+	client.SecretSyncPolicyLister = kssclientset.NewSecretSyncPolicyLister(client.SecretSyncPolicyInformer.GetIndexer())
+
+	client.DynamicInformerFactory = dynamicinformer.NewDynamicSharedInformerFactory(client.DynamicClientset, 0)
+	client.resourceInformers = make(map[schema.GroupVersionResource]informers.GenericInformer)
+
+	return nil
+}
+
+// AddEventHandlers registers the event handlers that feed the work queue from the informers.
+func (client *Client) AddEventHandlers() {
+	client.SecretInformer.AddEventHandler(client.SecretEventHandler())
+	client.NamespaceInformer.AddEventHandler(client.NamespaceEventHandler())
+	client.SecretSyncRuleInformer.AddEventHandler(client.SecretSyncRuleEventHandler())
+	client.ConfigMapInformer.AddEventHandler(client.ConfigMapEventHandler())
+	client.ConfigMapSyncRuleInformer.AddEventHandler(client.ConfigMapSyncRuleEventHandler())
+	client.ResourceSyncRuleInformer.AddEventHandler(client.ResourceSyncRuleEventHandler())
+	client.NamespacedSecretSyncRuleInformer.AddEventHandler(client.NamespacedSecretSyncRuleEventHandler())
+	client.SecretSyncPolicyInformer.AddEventHandler(client.SecretSyncPolicyEventHandler())
+}
+
+// StartInformers starts all informers and blocks until their caches have synced.
+func (client *Client) StartInformers(stopCh <-chan struct{}) bool {
+	client.InformerFactory.Start(stopCh)
+	go client.SecretSyncRuleInformer.Run(stopCh)
+	go client.ConfigMapSyncRuleInformer.Run(stopCh)
+	go client.ResourceSyncRuleInformer.Run(stopCh)
+	go client.NamespacedSecretSyncRuleInformer.Run(stopCh)
+	go client.SecretSyncPolicyInformer.Run(stopCh)
+
+	log.Debug("waiting for informer caches to sync")
+	return cache.WaitForCacheSync(stopCh,
+		client.SecretInformer.HasSynced,
+		client.NamespaceInformer.HasSynced,
+		client.SecretSyncRuleInformer.HasSynced,
+		client.ConfigMapInformer.HasSynced,
+		client.ConfigMapSyncRuleInformer.HasSynced,
+		client.ResourceSyncRuleInformer.HasSynced,
+		client.NamespacedSecretSyncRuleInformer.HasSynced,
+		client.SecretSyncPolicyInformer.HasSynced,
+	)
+}
+
+// ruleIndexFunc indexes synced Secrets and ConfigMaps by the name of the rule that created them.
+func ruleIndexFunc(obj interface{}) ([]string, error) {
+	switch obj := obj.(type) {
+	case *v1.Secret:
+		return syncedByRuleNames(obj, kssclientset.SecretSyncRule), nil
+	case *v1.ConfigMap:
+		return syncedByRuleNames(obj, kssclientset.ConfigMapSyncRule), nil
+	}
+
+	return nil, nil
+}
+
+// ownerRuleNames returns the names of the rules of the given kind owning the given managed object.
+func ownerRuleNames(obj metav1.Object, kind string) []string {
+	if !IsManagedBy(obj) {
+		return nil
+	}
+
+	return syncedByRuleNames(obj, kind)
+}
+
+// syncedByRuleNames returns the names of the rules of the given kind that the given object was synced by,
+// read from its rule labels and owner references. Rules of namespaced kinds are returned as namespace/name keys.
+func syncedByRuleNames(obj metav1.Object, kind string) []string {
+	rules := sets.NewString()
+
+	if name, ok := obj.GetLabels()[constants.RuleLabelKey]; ok && syncedByRuleKind(obj, kind) == kind {
+		if namespace, ok := obj.GetLabels()[constants.RuleNamespaceLabelKey]; ok {
+			name = namespace + "/" + name
+		}
+		rules.Insert(name)
+	}
+
+	for _, owner := range obj.GetOwnerReferences() {
+		if owner.Kind == kind {
+			rules.Insert(owner.Name)
+		}
+	}
+
+	return rules.List()
+}
+
+// syncedByRuleKind returns the kind of the rule that the given object was synced by, read from its rule kind label.
+// Objects without one predate the label and are attributed to the kind of their owner reference to the rule named by
+// their rule label, or else to the given kind. Secrets synced by NamespacedSecretSyncRules count as synced by a SecretSyncRule,
+// which they are keyed like.
+func syncedByRuleKind(obj metav1.Object, kind string) string {
+	labels := obj.GetLabels()
+
+	ruleKind, ok := labels[constants.RuleKindLabelKey]
+	if !ok {
+		ruleKind = kind
+		for _, owner := range obj.GetOwnerReferences() {
+			if owner.APIVersion == kssclientset.SchemeGroupVersion.String() && owner.Name == labels[constants.RuleLabelKey] {
+				ruleKind = owner.Kind
+				break
+			}
+		}
+	}
+
+	if ruleKind == kssclientset.NamespacedSecretSyncRule {
+		return kssclientset.SecretSyncRule
+	}
+	return ruleKind
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"os"
+
+	"github.com/alehechka/kube-secret-sync/constants"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// RunWithLeaderElection blocks until a Lease in the pod namespace is acquired and then runs the controller until
+// either the context is cancelled or leadership is lost. The Lease is released on cancellation so that a standby
+// replica can take over without waiting for it to expire.
+func (client *Client) RunWithLeaderElection(ctx context.Context) error {
+	config := client.SyncConfig
+
+	if config.PodNamespace == "" {
+		return constants.ErrPodNamespaceRequired
+	}
+
+	identity, err := leaderElectionIdentity()
+	if err != nil {
+		return err
+	}
+
+	logger := log.WithFields(log.Fields{"name": config.LeaseName, "kind": "Lease", "namespace": config.PodNamespace})
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      config.LeaseName,
+			Namespace: config.PodNamespace,
+		},
+		Client:     client.DefaultClientset.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+	}
+
+	started := make(chan struct{})
+	done := make(chan error, 1)
+
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Name:            config.LeaseName,
+		Lock:            lock,
+		ReleaseOnCancel: true,
+		LeaseDuration:   config.LeaseDuration,
+		RenewDeadline:   config.RenewDeadline,
+		RetryPeriod:     config.RetryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				logger.Infof("acquired leadership as %s", identity)
+				close(started)
+				done <- client.Run(ctx)
+			},
+			OnStoppedLeading: func() {
+				logger.Infof("stopped leading as %s", identity)
+			},
+			OnNewLeader: func(leader string) {
+				if leader != identity {
+					logger.Infof("current leader is %s", leader)
+				}
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("waiting to acquire leadership as %s", identity)
+	elector.Run(ctx)
+
+	if ctx.Err() == nil {
+		return constants.ErrLeaderElectionLost
+	}
+
+	select {
+	case <-started:
+		return <-done
+	default:
+		return nil
+	}
+}
+
+// leaderElectionIdentity returns a unique identity for this process, prefixed with the hostname (the pod name in-cluster).
+func leaderElectionIdentity() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", err
+	}
+
+	return hostname + "_" + string(uuid.NewUUID()), nil
+}
@@ -0,0 +1,81 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pkg "github.com/alehechka/kube-secret-sync/client"
+	"github.com/alehechka/kube-secret-sync/constants"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const keyLease string = "test-lease"
+
+func leaderElectionConfig() *pkg.SyncConfig {
+	return &pkg.SyncConfig{
+		PodNamespace:  keyDefault,
+		LeaderElect:   true,
+		LeaseName:     keyLease,
+		LeaseDuration: 2 * time.Second,
+		RenewDeadline: time.Second,
+		RetryPeriod:   100 * time.Millisecond,
+	}
+}
+
+func Test_RunWithLeaderElection_NoPodNamespace(t *testing.T) {
+	client := InitializeTestClientset()
+	client.SyncConfig = leaderElectionConfig()
+	client.SyncConfig.PodNamespace = ""
+
+	err := client.RunWithLeaderElection(context.Background())
+	assert.ErrorIs(t, err, constants.ErrPodNamespaceRequired)
+}
+
+func Test_RunWithLeaderElection_ReleasesOnCancel(t *testing.T) {
+	client := InitializeTestClientset()
+	client.SyncConfig = leaderElectionConfig()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- client.RunWithLeaderElection(ctx) }()
+
+	leases := client.DefaultClientset.CoordinationV1().Leases(keyDefault)
+	assert.Eventually(t, func() bool {
+		lease, err := leases.Get(ctx, keyLease, metav1.GetOptions{})
+		return err == nil && lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity != ""
+	}, 5*time.Second, 50*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-result)
+
+	lease, err := leases.Get(context.Background(), keyLease, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "", *lease.Spec.HolderIdentity)
+}
+
+func Test_RunWorker_StopsOnCancel(t *testing.T) {
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, testSecretSyncRule)
+	client.Enqueue(testSecretSyncRule)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client.RunWorker(ctx)
+
+	assert.Equal(t, 1, client.Queue.Len())
+	_, err := client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.Error(t, err)
+}
+
+func Test_RunWorker_ProcessesUntilShutdown(t *testing.T) {
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, testSecretSyncRule)
+	client.Enqueue(testSecretSyncRule)
+	client.Queue.ShutDown()
+
+	client.RunWorker(context.Background())
+
+	_, err := client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+}
@@ -4,16 +4,41 @@ import (
 	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 func ruleLogger(rule *typesv1.SecretSyncRule) *log.Entry {
+	if rule.Namespace != "" {
+		return namespacedRuleNameLogger(rule.Namespace, rule.Name)
+	}
+
 	return ruleNameLogger(rule.Name)
 }
 
+func clusterLogger(rule *typesv1.SecretSyncRule, cluster string) *log.Entry {
+	return ruleLogger(rule).WithField("cluster", cluster)
+}
+
 func ruleNameLogger(name string) *log.Entry {
 	return log.WithFields(log.Fields{"name": name, "kind": "SecretSyncRule"})
 }
 
+func namespacedRuleLogger(rule *typesv1.NamespacedSecretSyncRule) *log.Entry {
+	return namespacedRuleNameLogger(rule.Namespace, rule.Name)
+}
+
+func namespacedRuleNameLogger(namespace, name string) *log.Entry {
+	return log.WithFields(log.Fields{"name": name, "kind": "NamespacedSecretSyncRule", "namespace": namespace})
+}
+
+func configMapRuleLogger(rule *typesv1.ConfigMapSyncRule) *log.Entry {
+	return configMapRuleNameLogger(rule.Name)
+}
+
+func configMapRuleNameLogger(name string) *log.Entry {
+	return log.WithFields(log.Fields{"name": name, "kind": "ConfigMapSyncRule"})
+}
+
 func namespaceLogger(namespace *v1.Namespace) *log.Entry {
 	return namespaceNameLogger(namespace.Name)
 }
@@ -34,3 +59,28 @@ func secretLogger(secret *v1.Secret, namespaces ...*v1.Namespace) *log.Entry {
 func secretNameLogger(namespace, name string) *log.Entry {
 	return log.WithFields(log.Fields{"name": name, "kind": "Secret", "namespace": namespace})
 }
+
+func configMapLogger(configMap *v1.ConfigMap, namespaces ...*v1.Namespace) *log.Entry {
+	namespace := configMap.Namespace
+	if len(namespaces) > 0 {
+		namespace = namespaces[0].Name
+	}
+
+	return configMapNameLogger(namespace, configMap.Name)
+}
+
+func configMapNameLogger(namespace, name string) *log.Entry {
+	return log.WithFields(log.Fields{"name": name, "kind": "ConfigMap", "namespace": namespace})
+}
+
+func resourceRuleLogger(rule *typesv1.ResourceSyncRule) *log.Entry {
+	return resourceRuleNameLogger(rule.Name)
+}
+
+func resourceRuleNameLogger(name string) *log.Entry {
+	return log.WithFields(log.Fields{"name": name, "kind": "ResourceSyncRule"})
+}
+
+func resourceLogger(resource *unstructured.Unstructured) *log.Entry {
+	return log.WithFields(log.Fields{"name": resource.GetName(), "kind": resource.GetKind(), "namespace": resource.GetNamespace()})
+}
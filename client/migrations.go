@@ -0,0 +1,82 @@
+package client
+
+import (
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// MigrateOwnerReferences rewrites the owner references of synced Secrets created by earlier versions,
+// which referenced SecretSyncRules without an API version and with a random UID.
+// Copies referencing rules that no longer exist are left untouched and their rule is queued so that the copies get pruned.
+func (client *Client) MigrateOwnerReferences() {
+	secrets, err := client.SecretLister.List(labels.Everything())
+	if err != nil {
+		log.Errorf("failed to list secrets for owner reference migration: %s", err.Error())
+		return
+	}
+
+	migrated := 0
+	for _, secret := range secrets {
+		references, changed := client.migrateOwnerReferences(secret.OwnerReferences)
+		if !changed {
+			continue
+		}
+
+		if err := client.UpdateOwnerReferences(secret, references); err == nil {
+			migrated++
+		}
+	}
+
+	if migrated > 0 {
+		log.Infof("migrated owner references of %d synced secrets", migrated)
+	}
+}
+
+func (client *Client) migrateOwnerReferences(references []metav1.OwnerReference) (migrated []metav1.OwnerReference, changed bool) {
+	for _, reference := range references {
+		if reference.Kind != clientset.SecretSyncRule {
+			migrated = append(migrated, reference)
+			continue
+		}
+
+		rule, err := client.SecretSyncRuleLister.Get(reference.Name)
+		if err != nil {
+			client.Queue.Add(reference.Name)
+			migrated = append(migrated, reference)
+			continue
+		}
+
+		// rules that keep their copies drop the owner reference on their next sync instead
+		if rule.Spec.RuleDeletionPolicy() != typesv1.DeletionPolicyDelete {
+			migrated = append(migrated, reference)
+			continue
+		}
+
+		if !OwnerReferencesAreValid([]metav1.OwnerReference{reference}, rule) {
+			changed = true
+		}
+		migrated = append(migrated, OwnerReference(rule))
+	}
+
+	return
+}
+
+// UpdateOwnerReferences replaces the owner references of the given Secret.
+func (client *Client) UpdateOwnerReferences(secret *v1.Secret, references []metav1.OwnerReference) error {
+	logger := secretLogger(secret)
+	logger.Infof("updating owner references")
+
+	updated := secret.DeepCopy()
+	updated.OwnerReferences = references
+
+	_, err := client.DefaultClientset.CoreV1().Secrets(secret.Namespace).Update(client.Context, updated, metav1.UpdateOptions{})
+	if err != nil {
+		logger.Errorf("failed to update owner references - %s", err.Error())
+	}
+
+	return err
+}
@@ -0,0 +1,56 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
+	pkg "github.com/alehechka/kube-secret-sync/client"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+func Test_OwnerReference(t *testing.T) {
+	rule := testSecretSyncRule.DeepCopy()
+	rule.UID = uuid.NewUUID()
+
+	reference := pkg.OwnerReference(rule)
+
+	assert.Equal(t, "kube-secret-sync.io/v1", reference.APIVersion)
+	assert.Equal(t, clientset.SecretSyncRule, reference.Kind)
+	assert.Equal(t, rule.Name, reference.Name)
+	assert.Equal(t, rule.UID, reference.UID)
+	assert.True(t, *reference.BlockOwnerDeletion)
+	assert.True(t, *reference.Controller)
+}
+
+func Test_MigrateOwnerReferences(t *testing.T) {
+	rule := testSecretSyncRule.DeepCopy()
+	rule.UID = uuid.NewUUID()
+
+	copy := PrepareSecret(rule, testNamespace, defaultSecret)
+	copy.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: clientset.GroupName,
+		Kind:       clientset.SecretSyncRule,
+		Name:       rule.Name,
+		UID:        uuid.NewUUID(),
+	}}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, copy, rule)
+
+	client.MigrateOwnerReferences()
+
+	secret, err := client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.Equal(t, []metav1.OwnerReference{pkg.OwnerReference(rule)}, secret.OwnerReferences)
+}
+
+func Test_MigrateOwnerReferences_NoRule(t *testing.T) {
+	copy := PrepareSecret(testSecretSyncRule, testNamespace, defaultSecret)
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, copy)
+
+	client.MigrateOwnerReferences()
+
+	assert.Equal(t, 1, client.Queue.Len())
+}
@@ -0,0 +1,183 @@
+package client
+
+import (
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/cache"
+)
+
+func (client *Client) NamespacedSecretSyncRuleEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    client.AddedNamespacedSecretSyncRuleHandler,
+		UpdateFunc: client.ModifiedNamespacedSecretSyncRuleHandler,
+		DeleteFunc: client.DeletedNamespacedSecretSyncRuleHandler,
+	}
+}
+
+func (client *Client) AddedNamespacedSecretSyncRuleHandler(obj interface{}) {
+	rule, ok := obj.(*typesv1.NamespacedSecretSyncRule)
+	if !ok {
+		log.Error("failed to cast NamespacedSecretSyncRule")
+		return
+	}
+
+	namespacedRuleLogger(rule).Infof("added")
+	client.EnqueueNamespacedSecretSyncRule(rule)
+}
+
+// ModifiedNamespacedSecretSyncRuleHandler handles syncing secrets after a NamespacedSecretSyncRule has been modified,
+// the same way ModifiedSecretSyncRuleHandler does for SecretSyncRules.
+func (client *Client) ModifiedNamespacedSecretSyncRuleHandler(oldObj, newObj interface{}) {
+	rule, ok := newObj.(*typesv1.NamespacedSecretSyncRule)
+	if !ok {
+		log.Error("failed to cast NamespacedSecretSyncRule")
+		return
+	}
+
+	if old, ok := oldObj.(*typesv1.NamespacedSecretSyncRule); ok {
+		if old.ResourceVersion == rule.ResourceVersion {
+			namespacedRuleLogger(rule).Debugf("periodic resync")
+			client.EnqueueNamespacedSecretSyncRule(rule)
+			return
+		}
+
+		if old.Generation == rule.Generation && old.DeletionTimestamp.Equal(rule.DeletionTimestamp) {
+			namespacedRuleLogger(rule).Debugf("status or metadata updated")
+			return
+		}
+	}
+
+	namespacedRuleLogger(rule).Infof("modified")
+	client.EnqueueNamespacedSecretSyncRule(rule)
+}
+
+func (client *Client) DeletedNamespacedSecretSyncRuleHandler(obj interface{}) {
+	rule, ok := objectFromTombstone(obj).(*typesv1.NamespacedSecretSyncRule)
+	if !ok {
+		log.Error("failed to cast NamespacedSecretSyncRule")
+		return
+	}
+
+	namespacedRuleLogger(rule).Infof("deleted")
+	client.EnqueueNamespacedSecretSyncRule(rule)
+}
+
+func (client *Client) SecretSyncPolicyEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    client.SecretSyncPolicyHandler,
+		UpdateFunc: func(oldObj, newObj interface{}) { client.SecretSyncPolicyHandler(newObj) },
+		DeleteFunc: client.SecretSyncPolicyHandler,
+	}
+}
+
+// SecretSyncPolicyHandler queues every NamespacedSecretSyncRule after a SecretSyncPolicy changed,
+// since the namespaces that any of them may sync to could have changed.
+func (client *Client) SecretSyncPolicyHandler(obj interface{}) {
+	policy, ok := objectFromTombstone(obj).(*typesv1.SecretSyncPolicy)
+	if !ok {
+		log.Error("failed to cast SecretSyncPolicy")
+		return
+	}
+
+	rules, err := client.NamespacedSecretSyncRuleLister.List(labels.Everything())
+	if err != nil {
+		log.Errorf("failed to list NamespacedSecretSyncRules: %s", err.Error())
+		return
+	}
+
+	log.WithFields(log.Fields{"name": policy.Name, "kind": "SecretSyncPolicy"}).Debugf("queueing %d namespaced rules", len(rules))
+	for _, rule := range rules {
+		client.EnqueueNamespacedSecretSyncRule(rule)
+	}
+}
+
+// SyncNamespacedSecretSyncRule reconciles the NamespacedSecretSyncRule with the given namespace and name against the informer caches,
+// the same way SyncSecretSyncRule does for SecretSyncRules.
+// A rule referencing Secrets outside of its own namespace is reported in its status and leaves existing copies untouched.
+func (client *Client) SyncNamespacedSecretSyncRule(namespace, name string) error {
+	key := namespace + "/" + name
+
+	rule, err := client.NamespacedSecretSyncRuleLister.Get(namespace, name)
+	if errors.IsNotFound(err) {
+		logger := namespacedRuleNameLogger(namespace, name)
+		logger.Debugf("no longer exists, removing synced secrets")
+
+		summary := new(SyncSummary)
+		err := client.PruneOwnedSecrets(key, typesv1.Rules{}, nil, RuleDeletionPolicyOf, summary)
+		summary.Log(logger)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if rule.DeletionTimestamp != nil {
+		return client.FinalizeNamespacedSecretSyncRule(rule)
+	}
+
+	if !HasFinalizer(rule) {
+		if rule, err = client.AddNamespacedSecretSyncRuleFinalizer(rule); err != nil {
+			return err
+		}
+	}
+
+	logger := namespacedRuleLogger(rule)
+	logger.Debugf("syncing")
+
+	summary := new(SyncSummary)
+	converted, err := client.ConvertNamespacedSecretSyncRule(rule)
+	if err != nil {
+		logger.Errorf("invalid rule: %s", err.Error())
+		summary.InvalidSource = err.Error()
+	} else {
+		err = client.syncSecretSyncRule(converted, summary)
+	}
+	summary.Log(logger)
+
+	if IsRuleError(err) {
+		err = nil
+	}
+
+	return utilerrors.NewAggregate([]error{err, client.UpdateNamespacedSecretSyncRuleStatus(rule, summary)})
+}
+
+// ConvertNamespacedSecretSyncRule converts the given NamespacedSecretSyncRule into the SecretSyncRule synced on its behalf,
+// restricted to the target namespaces allowed by the cached SecretSyncPolicies.
+func (client *Client) ConvertNamespacedSecretSyncRule(rule *typesv1.NamespacedSecretSyncRule) (*typesv1.SecretSyncRule, error) {
+	namespace, err := client.NamespaceLister.Get(rule.Namespace)
+	if err != nil {
+		namespace = &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: rule.Namespace}}
+	}
+
+	policies, err := client.SecretSyncPolicyLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	return rule.SecretSyncRule(namespace, policies)
+}
+
+// ListNamespacedSecretSyncRules returns every cached NamespacedSecretSyncRule converted into the SecretSyncRule synced on its behalf.
+// Rules that cannot be converted are left out.
+func (client *Client) ListNamespacedSecretSyncRules() (rules []*typesv1.SecretSyncRule, err error) {
+	namespaced, err := client.NamespacedSecretSyncRuleLister.List(labels.Everything())
+	if err != nil {
+		log.Errorf("failed to list NamespacedSecretSyncRules: %s", err.Error())
+		return nil, err
+	}
+
+	for _, rule := range namespaced {
+		converted, err := client.ConvertNamespacedSecretSyncRule(rule)
+		if err != nil {
+			continue
+		}
+		rules = append(rules, converted)
+	}
+
+	return
+}
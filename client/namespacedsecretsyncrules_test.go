@@ -0,0 +1,113 @@
+package client_test
+
+import (
+	"testing"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	pkg "github.com/alehechka/kube-secret-sync/client"
+	"github.com/alehechka/kube-secret-sync/constants"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_SyncNamespacedSecretSyncRule(t *testing.T) {
+	other := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other-namespace"}}
+	client := InitializeTestClientset(defaultNamespace, testNamespace, other, defaultSecret, testNamespacedSecretSyncRule, testSecretSyncPolicy)
+
+	err := client.SyncSecretSyncRule(keyDefault + "/" + keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err := client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.True(t, pkg.IsManagedBy(secret))
+	assert.Empty(t, secret.OwnerReferences)
+	assert.Equal(t, keyDefault, secret.Labels[constants.RuleNamespaceLabelKey])
+
+	_, err = client.GetSecret(other.Name, keyDefaultSecret)
+	assert.True(t, errors.IsNotFound(err))
+
+	rule, err := client.KubeSecretSyncClientset.NamespacedSecretSyncRules(keyDefault).Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, meta.IsStatusConditionTrue(rule.Status.Conditions, typesv1.ConditionReady))
+	assert.Equal(t, []string{keyTestNamespace}, rule.Status.SyncedNamespaces)
+}
+
+func Test_SyncNamespacedSecretSyncRule_NoPolicy(t *testing.T) {
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, testNamespacedSecretSyncRule)
+
+	err := client.SyncSecretSyncRule(keyDefault + "/" + keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	_, err = client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func Test_SyncNamespacedSecretSyncRule_CrossNamespaceSource(t *testing.T) {
+	rule := testNamespacedSecretSyncRule.DeepCopy()
+	rule.Spec.Secret.Namespace = keyTestNamespace
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, testSecret, rule, testSecretSyncPolicy)
+
+	err := client.SyncSecretSyncRule(keyDefault + "/" + keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	rule, err = client.KubeSecretSyncClientset.NamespacedSecretSyncRules(keyDefault).Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	condition := meta.FindStatusCondition(rule.Status.Conditions, typesv1.ConditionSourceFound)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Contains(t, condition.Message, keyTestNamespace)
+}
+
+func Test_SyncNamespacedSecretSyncRule_NoRule(t *testing.T) {
+	converted, err := testNamespacedSecretSyncRule.SecretSyncRule(defaultNamespace, nil)
+	assert.NoError(t, err)
+	copy := PrepareSecret(converted, testNamespace, defaultSecret)
+
+	clusterRule := testSecretSyncRule.DeepCopy()
+	clusterRule.Name = keyTestSecretSyncRule
+	clusterRule.Spec.Rules.Namespaces.Exclude = []string{keyTestNamespace}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, copy, clusterRule)
+
+	err = client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	_, err = client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err, "the copy of a namespaced rule must not be pruned by a cluster rule of the same name")
+
+	err = client.SyncSecretSyncRule(keyDefault + "/" + keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	_, err = client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func Test_SyncNamespacedSecretSyncRule_CopyOfOtherRule(t *testing.T) {
+	clusterCopy := PrepareSecret(testSecretSyncRule, testNamespace, defaultSecret)
+	clusterCopy.Data = map[string][]byte{"owned": []byte("by the cluster rule")}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, clusterCopy, testSecretSyncRule, testNamespacedSecretSyncRule, testSecretSyncPolicy)
+
+	err := client.SyncSecretSyncRule(keyDefault + "/" + keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err := client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.Equal(t, clusterCopy.Data, secret.Data)
+	assert.NotContains(t, secret.Labels, constants.RuleNamespaceLabelKey)
+
+	rule, err := client.KubeSecretSyncClientset.NamespacedSecretSyncRules(keyDefault).Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, meta.IsStatusConditionTrue(rule.Status.Conditions, typesv1.ConditionConflict))
+	assert.Equal(t, keyTestNamespace, rule.Status.Conflicts[0].Namespace)
+
+	err = client.PruneOwnedSecrets(keyDefault+"/"+keyTestSecretSyncRule, typesv1.Rules{}, nil, pkg.RuleDeletionPolicyOf, new(pkg.SyncSummary))
+	assert.NoError(t, err)
+
+	_, err = client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err, "a namespaced rule must not delete the copy of another rule")
+}
@@ -1,70 +1,91 @@
 package client
 
 import (
+	"reflect"
+
 	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
 )
 
-func (client *Client) NamespaceEventHandler(event watch.Event) error {
-	namespace, ok := event.Object.(*v1.Namespace)
-	if !ok {
-		log.Error("failed to cast Namespace")
-		return nil
+func (client *Client) NamespaceEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    client.AddedNamespaceHandler,
+		UpdateFunc: client.ModifiedNamespaceHandler,
 	}
+}
 
-	switch event.Type {
-	case watch.Added:
-		return client.AddedNamespaceHandler(namespace)
+func (client *Client) AddedNamespaceHandler(obj interface{}) {
+	namespace, ok := obj.(*v1.Namespace)
+	if !ok {
+		log.Error("failed to cast Namespace")
+		return
 	}
 
-	return nil
-}
-
-func (client *Client) AddedNamespaceHandler(namespace *v1.Namespace) error {
 	logger := namespaceLogger(namespace)
 
 	if namespace.CreationTimestamp.Time.Before(client.StartTime) {
-		logger.Debugf("namespace will be synced on startup by SecretSyncRule watcher")
-		return nil
+		logger.Debugf("namespace will be synced on startup by SecretSyncRule informer")
+		return
 	}
 
 	logger.Infof("added")
-	return client.SyncNamespace(namespace)
+	client.SyncNamespace(namespace)
 }
 
-func (client *Client) SyncNamespace(namespace *v1.Namespace) error {
-	namespaceLogger(namespace).Debugf("syncing new namespace")
+// ModifiedNamespaceHandler handles syncing secrets after the labels, annotations or phase of a Namespace changed.
+// Every SecretSyncRule, ConfigMapSyncRule and ResourceSyncRule that starts or stops targeting the Namespace is queued, which adds copies to newly matching
+// namespaces and prunes managed copies from namespaces that stopped matching.
+// Templated SecretSyncRules still targeting the Namespace are queued as well, since their copies may render differently,
+// and so are the NamespacedSecretSyncRules living in the Namespace, since other SecretSyncPolicies may apply to them.
+func (client *Client) ModifiedNamespaceHandler(oldObj, newObj interface{}) {
+	namespace, ok := newObj.(*v1.Namespace)
+	if !ok {
+		log.Error("failed to cast Namespace")
+		return
+	}
 
-	rules, err := client.ListSecretSyncRules()
-	if err != nil {
-		return err
+	old, ok := oldObj.(*v1.Namespace)
+	if !ok {
+		log.Error("failed to cast Namespace")
+		return
 	}
 
-	for _, rule := range rules.Items {
-		if rule.ShouldSyncNamespace(namespace) {
-			client.SyncSecretToNamespace(&rule, namespace)
-		}
+	if old.ResourceVersion == namespace.ResourceVersion || !NamespaceMetadataChanged(old, namespace) {
+		return
 	}
 
-	return nil
-}
+	count := client.enqueueMatching(func(rule *typesv1.SecretSyncRule) bool {
+		if rule.ShouldSyncNamespace(old) != rule.ShouldSyncNamespace(namespace) || rule.Namespace == namespace.Name {
+			return true
+		}
+		return rule.IsTemplated() && rule.ShouldSyncNamespace(namespace)
+	})
+	count += client.enqueueMatchingConfigMapRules(func(rule *typesv1.ConfigMapSyncRule) bool {
+		return rule.ShouldSyncNamespace(old) != rule.ShouldSyncNamespace(namespace)
+	})
+	count += client.enqueueMatchingResourceRules(func(rule *typesv1.ResourceSyncRule) bool {
+		return rule.ShouldSyncNamespace(old) != rule.ShouldSyncNamespace(namespace)
+	})
 
-func (client *Client) SyncSecretToNamespace(rule *typesv1.SecretSyncRule, namespace *v1.Namespace) error {
-	secret, err := client.GetSecret(rule.Spec.Secret.Namespace, rule.Spec.Secret.Name)
-	if err != nil {
-		return err
+	if count > 0 {
+		namespaceLogger(namespace).Infof("modified")
 	}
+}
 
-	return client.CreateUpdateSecret(rule, namespace, secret)
+// NamespaceMetadataChanged determines whether or not the labels, annotations or phase of a Namespace changed.
+func NamespaceMetadataChanged(old, updated *v1.Namespace) bool {
+	return old.Status.Phase != updated.Status.Phase ||
+		!reflect.DeepEqual(old.Labels, updated.Labels) ||
+		!reflect.DeepEqual(CopyAnnotations(old.Annotations), CopyAnnotations(updated.Annotations))
 }
 
-func (client *Client) ListNamespaces() (namespaces *v1.NamespaceList, err error) {
-	namespaces, err = client.DefaultClientset.CoreV1().Namespaces().List(client.Context, metav1.ListOptions{})
-	if err != nil {
-		log.Errorf("failed to list namespaces: %s", err.Error())
-	}
-	return
+// SyncNamespace adds every SecretSyncRule, ConfigMapSyncRule and ResourceSyncRule that targets the given Namespace to the work queue.
+func (client *Client) SyncNamespace(namespace *v1.Namespace) {
+	namespaceLogger(namespace).Debugf("syncing new namespace")
+
+	client.enqueueMatching(func(rule *typesv1.SecretSyncRule) bool { return rule.ShouldSyncNamespace(namespace) })
+	client.enqueueMatchingConfigMapRules(func(rule *typesv1.ConfigMapSyncRule) bool { return rule.ShouldSyncNamespace(namespace) })
+	client.enqueueMatchingResourceRules(func(rule *typesv1.ResourceSyncRule) bool { return rule.ShouldSyncNamespace(namespace) })
 }
@@ -3,44 +3,91 @@ package client_test
 import (
 	"testing"
 
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/constants"
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func Test_SyncSecretToNamespace(t *testing.T) {
-	client := InitializeTestClientset()
+func Test_ModifiedNamespaceHandler_Relabelled(t *testing.T) {
+	rule := testSecretSyncRule.DeepCopy()
+	rule.Spec.Rules.Namespaces.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}}
 
-	client.CreateSecret(testSecretSyncRule, defaultNamespace, defaultSecret)
+	client := InitializeTestClientset(rule)
 
-	err := client.SyncSecretToNamespace(testSecretSyncRule, testNamespace)
+	old := testNamespace.DeepCopy()
+	old.ResourceVersion = "1"
+	relabelled := old.DeepCopy()
+	relabelled.ResourceVersion = "2"
+	relabelled.Labels = map[string]string{"team": "payments"}
 
-	assert.NoError(t, err)
+	client.ModifiedNamespaceHandler(old, relabelled)
+	assert.Equal(t, 1, client.Queue.Len())
 }
 
-func Test_SyncSecretToNamespace_NoSecret(t *testing.T) {
-	client := InitializeTestClientset()
+func Test_ModifiedNamespaceHandler_Unchanged(t *testing.T) {
+	client := InitializeTestClientset(testSecretSyncRule)
 
-	err := client.SyncSecretToNamespace(testSecretSyncRule, testNamespace)
+	old := testNamespace.DeepCopy()
+	old.ResourceVersion = "1"
+	updated := old.DeepCopy()
+	updated.ResourceVersion = "2"
 
-	assert.Error(t, err)
+	client.ModifiedNamespaceHandler(old, updated)
+	assert.Equal(t, 0, client.Queue.Len())
 }
 
-func Test_ListNamespaces(t *testing.T) {
-	client := InitializeTestClientset()
+func Test_ModifiedNamespaceHandler_Terminating(t *testing.T) {
+	client := InitializeTestClientset(testSecretSyncRule)
 
-	client.DefaultClientset.CoreV1().Namespaces().Create(client.Context, testNamespace, metav1.CreateOptions{})
+	old := testNamespace.DeepCopy()
+	old.ResourceVersion = "1"
+	terminating := old.DeepCopy()
+	terminating.ResourceVersion = "2"
+	terminating.Status.Phase = v1.NamespaceTerminating
 
-	namespaces, err := client.ListNamespaces()
-	assert.NoError(t, err)
-	assert.NotNil(t, namespaces)
-	assert.Equal(t, 1, len(namespaces.Items))
+	client.ModifiedNamespaceHandler(old, terminating)
+	assert.Equal(t, 1, client.Queue.Len())
+}
+
+func Test_ModifiedNamespaceHandler_Templated(t *testing.T) {
+	rule := testSecretSyncRule.DeepCopy()
+	rule.Spec.Template = &typesv1.SecretTemplate{Data: map[string]string{"tier": "{{ .Namespace.Labels.tier }}"}}
+
+	client := InitializeTestClientset(rule)
+
+	old := testNamespace.DeepCopy()
+	old.ResourceVersion = "1"
+	old.Labels = map[string]string{"tier": "web"}
+	relabelled := old.DeepCopy()
+	relabelled.ResourceVersion = "2"
+	relabelled.Labels = map[string]string{"tier": "api"}
+
+	client.ModifiedNamespaceHandler(old, relabelled)
+	assert.Equal(t, 1, client.Queue.Len())
 }
 
-func Test_ListNamespaces_Empty(t *testing.T) {
-	client := InitializeTestClientset()
+func Test_SyncSecretSyncRule_RefusedNamespace(t *testing.T) {
+	refusing := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        keyExcludedNamespace,
+		Annotations: map[string]string{constants.RefuseSyncAnnotationKey: "true"},
+	}}
+	stale := PrepareSecret(testSecretSyncRule, refusing, defaultSecret)
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, refusing, defaultSecret, stale, testSecretSyncRule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	_, err = client.GetSecret(keyExcludedNamespace, keyDefaultSecret)
+	assert.True(t, errors.IsNotFound(err))
+
+	_, err = client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
 
-	namespaces, err := client.ListNamespaces()
+	rule, err := client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
 	assert.NoError(t, err)
-	assert.NotNil(t, namespaces)
-	assert.Equal(t, 0, len(namespaces.Items))
+	assert.Equal(t, []string{keyExcludedNamespace}, rule.Status.RefusedNamespaces)
 }
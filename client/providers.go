@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/constants"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SourceProvider reads the data of external sources from outside of the cluster.
+type SourceProvider interface {
+	// Name returns the name that external sources reference the provider by.
+	Name() string
+	// Data returns the data at the given path, or an error wrapping constants.ErrSourceNotFound if there is none.
+	Data(path string) (map[string][]byte, error)
+	// Watch calls changed with every path whose data may have changed until the given context is done.
+	Watch(ctx context.Context, changed func(path string)) error
+}
+
+// RegisterSourceProvider makes the given SourceProvider available to external sources by its name.
+func (client *Client) RegisterSourceProvider(provider SourceProvider) {
+	if client.SourceProviders == nil {
+		client.SourceProviders = make(map[string]SourceProvider)
+	}
+
+	client.SourceProviders[provider.Name()] = provider
+}
+
+// InitializeSourceProviders registers the source providers enabled by the SyncConfig.
+func (client *Client) InitializeSourceProviders() {
+	if client.SyncConfig.FileSourceDirectory != "" {
+		client.RegisterSourceProvider(NewFileProvider(client.SyncConfig.FileSourceDirectory, client.SyncConfig.FileSourcePollInterval))
+	}
+}
+
+// WatchSourceProviders watches every registered SourceProvider until the given context is done,
+// queueing the rules whose external sources changed.
+func (client *Client) WatchSourceProviders(ctx context.Context) {
+	for name, provider := range client.SourceProviders {
+		name, provider := name, provider
+
+		go func() {
+			err := provider.Watch(ctx, func(path string) { client.EnqueueExternalSource(name, path) })
+			if err != nil {
+				log.WithField("provider", name).Errorf("failed to watch source provider: %s", err.Error())
+			}
+		}()
+	}
+}
+
+// EnqueueExternalSource adds every SecretSyncRule reading the given changed path of the given provider to the work queue.
+func (client *Client) EnqueueExternalSource(provider, path string) {
+	matches := func(rule *typesv1.SecretSyncRule) bool {
+		return rule.UsesExternalSource(provider, path)
+	}
+
+	if count := client.enqueueMatching(matches); count > 0 {
+		log.WithFields(log.Fields{"provider": provider, "path": path}).Infof("external source changed, queueing %d rules", count)
+	}
+}
+
+// ExternalSecret reads the data of the given external source into a Secret with the name of the source,
+// in a namespace named after the provider-qualified identifier of the source so that its copies can be traced back to it.
+func (client *Client) ExternalSecret(source typesv1.ExternalSource) (*v1.Secret, error) {
+	provider, ok := client.SourceProviders[source.Provider]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", source.Provider, constants.ErrUnknownSourceProvider)
+	}
+
+	data, err := provider.Data(source.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: source.Name, Namespace: source.Identifier()},
+		Data:       data,
+		Type:       v1.SecretTypeOpaque,
+	}, nil
+}
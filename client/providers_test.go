@@ -0,0 +1,89 @@
+package client_test
+
+import (
+	"testing"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	pkg "github.com/alehechka/kube-secret-sync/client"
+	"github.com/alehechka/kube-secret-sync/constants"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// externalSecretSyncRule returns a SecretSyncRule syncing the given external sources to the test namespace.
+func externalSecretSyncRule(sources ...typesv1.ExternalSource) *typesv1.SecretSyncRule {
+	return &typesv1.SecretSyncRule{
+		ObjectMeta: metav1.ObjectMeta{Name: keyTestSecretSyncRule},
+		Spec: typesv1.SecretSyncRuleSpec{
+			External: sources,
+			Rules:    typesv1.Rules{Namespaces: typesv1.NamespaceRules{Include: []string{keyTestNamespace}}},
+		},
+	}
+}
+
+func Test_SyncSecretSyncRule_ExternalSource(t *testing.T) {
+	root := writeSourceFiles(t, map[string]string{"database.env": "USER=app"})
+	rule := externalSecretSyncRule(typesv1.ExternalSource{Name: "database", Provider: pkg.FileProviderName, Path: "database.env"})
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, rule)
+	client.RegisterSourceProvider(pkg.NewFileProvider(root, 0))
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err := client.DefaultClientset.CoreV1().Secrets(keyTestNamespace).Get(client.Context, "database", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, pkg.IsManagedBy(secret))
+	assert.Equal(t, []byte("app"), secret.Data["USER"])
+	assert.Equal(t, pkg.FileProviderName+":database.env", secret.Annotations[constants.SourceNamespaceAnnotationKey])
+	assert.Equal(t, "database", secret.Annotations[constants.SourceNameAnnotationKey])
+
+	_, err = client.DefaultClientset.CoreV1().Secrets(keyDefault).Get(client.Context, "database", metav1.GetOptions{})
+	assert.Error(t, err)
+}
+
+func Test_SyncSecretSyncRule_MissingExternalSource(t *testing.T) {
+	rule := externalSecretSyncRule(typesv1.ExternalSource{Name: "database", Provider: pkg.FileProviderName, Path: "database.env"})
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, rule)
+	client.RegisterSourceProvider(pkg.NewFileProvider(t.TempDir(), 0))
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	rule, err = client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	condition := meta.FindStatusCondition(rule.Status.Conditions, typesv1.ConditionSourceFound)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Contains(t, condition.Message, "file:database.env")
+}
+
+func Test_SyncSecretSyncRule_UnknownSourceProvider(t *testing.T) {
+	rule := externalSecretSyncRule(typesv1.ExternalSource{Name: "database", Provider: "vault", Path: "database"})
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	rule, err = client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	condition := meta.FindStatusCondition(rule.Status.Conditions, typesv1.ConditionSourceFound)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Contains(t, condition.Message, "unknown source provider")
+}
+
+func Test_EnqueueExternalSource(t *testing.T) {
+	rule := externalSecretSyncRule(typesv1.ExternalSource{Name: "tls", Provider: pkg.FileProviderName, Path: "tls"})
+
+	client := InitializeTestClientset(rule)
+
+	client.EnqueueExternalSource(pkg.FileProviderName, "database.env")
+	assert.Equal(t, 0, client.Queue.Len())
+
+	client.EnqueueExternalSource(pkg.FileProviderName, "tls/tls.crt")
+	assert.Equal(t, 1, client.Queue.Len())
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	kssclientset "github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// maxRetries is the number of times a rule will be retried before it is dropped out of the queue.
+const maxRetries = 15
+
+// configMapSyncRuleKeyPrefix prefixes the work queue keys of ConfigMapSyncRules,
+// which would otherwise collide with SecretSyncRules of the same name.
+const configMapSyncRuleKeyPrefix = kssclientset.ConfigMapSyncRule + "/"
+
+// resourceSyncRuleKeyPrefix prefixes the work queue keys of ResourceSyncRules for the same reason.
+const resourceSyncRuleKeyPrefix = kssclientset.ResourceSyncRule + "/"
+
+func (client *Client) InitializeQueue() {
+	client.Queue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "SyncRules")
+}
+
+// Enqueue adds the given SecretSyncRule to the work queue.
+func (client *Client) Enqueue(rule *typesv1.SecretSyncRule) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(rule)
+	if err != nil {
+		ruleLogger(rule).Errorf("failed to get key: %s", err.Error())
+		return
+	}
+
+	client.Queue.Add(key)
+}
+
+// EnqueueNamespacedSecretSyncRule adds the given NamespacedSecretSyncRule to the work queue.
+// Its namespace/name key cannot collide with the name of a SecretSyncRule.
+func (client *Client) EnqueueNamespacedSecretSyncRule(rule *typesv1.NamespacedSecretSyncRule) {
+	client.Queue.Add(rule.Namespace + "/" + rule.Name)
+}
+
+// EnqueueConfigMapSyncRule adds the given ConfigMapSyncRule to the work queue.
+func (client *Client) EnqueueConfigMapSyncRule(rule *typesv1.ConfigMapSyncRule) {
+	client.Queue.Add(configMapSyncRuleKeyPrefix + rule.Name)
+}
+
+// EnqueueResourceSyncRule adds the given ResourceSyncRule to the work queue.
+func (client *Client) EnqueueResourceSyncRule(rule *typesv1.ResourceSyncRule) {
+	client.Queue.Add(resourceSyncRuleKeyPrefix + rule.Name)
+}
+
+// RunWorker processes items from the work queue until it is shut down or the given context is cancelled.
+// Items still queued on cancellation are left unprocessed, so that a replica losing leadership stops syncing
+// right after the item in progress instead of draining the queue alongside the new leader.
+func (client *Client) RunWorker(ctx context.Context) {
+	for ctx.Err() == nil && client.processNextItem(ctx) {
+	}
+}
+
+func (client *Client) processNextItem(ctx context.Context) bool {
+	item, shutdown := client.Queue.Get()
+	if shutdown {
+		return false
+	}
+	defer client.Queue.Done(item)
+
+	if ctx.Err() != nil {
+		return false
+	}
+
+	key := item.(string)
+
+	var err error
+	switch {
+	case strings.HasPrefix(key, configMapSyncRuleKeyPrefix):
+		err = client.SyncConfigMapSyncRule(strings.TrimPrefix(key, configMapSyncRuleKeyPrefix))
+	case strings.HasPrefix(key, resourceSyncRuleKeyPrefix):
+		err = client.SyncResourceSyncRule(strings.TrimPrefix(key, resourceSyncRuleKeyPrefix))
+	default:
+		err = client.SyncSecretSyncRule(key)
+	}
+	client.handleErr(err, key)
+
+	return true
+}
+
+func (client *Client) handleErr(err error, key string) {
+	if err == nil {
+		client.Queue.Forget(key)
+		return
+	}
+
+	logger := ruleNameLogger(key)
+	switch {
+	case strings.HasPrefix(key, configMapSyncRuleKeyPrefix):
+		logger = configMapRuleNameLogger(strings.TrimPrefix(key, configMapSyncRuleKeyPrefix))
+	case strings.HasPrefix(key, resourceSyncRuleKeyPrefix):
+		logger = resourceRuleNameLogger(strings.TrimPrefix(key, resourceSyncRuleKeyPrefix))
+	}
+
+	if client.Queue.NumRequeues(key) < maxRetries {
+		logger.Warnf("failed to sync, retrying: %s", err.Error())
+		client.Queue.AddRateLimited(key)
+		return
+	}
+
+	logger.Errorf("dropping out of the queue after %d retries: %s", maxRetries, err.Error())
+	client.Queue.Forget(key)
+}
+
+// enqueueMatching adds every cached SecretSyncRule and converted NamespacedSecretSyncRule that matches to the work queue
+// and returns the number added.
+func (client *Client) enqueueMatching(matches func(rule *typesv1.SecretSyncRule) bool) (count int) {
+	rules, err := client.ListSecretSyncRules()
+	if err != nil {
+		return
+	}
+
+	namespaced, err := client.ListNamespacedSecretSyncRules()
+	if err != nil {
+		return
+	}
+	rules = append(rules, namespaced...)
+
+	for _, rule := range rules {
+		if matches(rule) {
+			client.Enqueue(rule)
+			count++
+		}
+	}
+
+	return
+}
+
+// enqueueMatchingConfigMapRules adds every cached ConfigMapSyncRule that matches to the work queue and returns the number added.
+func (client *Client) enqueueMatchingConfigMapRules(matches func(rule *typesv1.ConfigMapSyncRule) bool) (count int) {
+	rules, err := client.ListConfigMapSyncRules()
+	if err != nil {
+		return
+	}
+
+	for _, rule := range rules {
+		if matches(rule) {
+			client.EnqueueConfigMapSyncRule(rule)
+			count++
+		}
+	}
+
+	return
+}
+
+// enqueueMatchingResourceRules adds every cached ResourceSyncRule that matches to the work queue and returns the number added.
+func (client *Client) enqueueMatchingResourceRules(matches func(rule *typesv1.ResourceSyncRule) bool) (count int) {
+	rules, err := client.ListResourceSyncRules()
+	if err != nil {
+		return
+	}
+
+	for _, rule := range rules {
+		if matches(rule) {
+			client.EnqueueResourceSyncRule(rule)
+			count++
+		}
+	}
+
+	return
+}
+
+// objectFromTombstone unwraps the final state of an object that was deleted while the watch was disconnected.
+func objectFromTombstone(obj interface{}) interface{} {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		return tombstone.Obj
+	}
+	return obj
+}
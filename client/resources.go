@@ -0,0 +1,383 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
+	"github.com/alehechka/kube-secret-sync/constants"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// resourceCacheSyncTimeout bounds the wait for a newly started resource informer,
+// so that a kind the controller is not allowed to list does not block the worker.
+const resourceCacheSyncTimeout = 10 * time.Second
+
+// maxResourceInformerBackoff caps the time that a resource informer which failed to sync is not waited for again.
+const maxResourceInformerBackoff = 5 * time.Minute
+
+// resourceInformerFailure records when the informer of a kind last failed to sync and when it may be waited for again.
+type resourceInformerFailure struct {
+	backoff time.Duration
+	retryAt time.Time
+}
+
+// serverManagedFields are the metadata fields set by the API server that are never copied to synced resources.
+var serverManagedFields = []string{
+	"uid", "resourceVersion", "generation", "creationTimestamp", "deletionTimestamp", "deletionGracePeriodSeconds",
+	"selfLink", "managedFields", "ownerReferences", "finalizers", "generateName",
+}
+
+func (client *Client) ResourceEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    client.EnqueueResource,
+		UpdateFunc: client.ModifiedResourceHandler,
+		DeleteFunc: client.EnqueueResource,
+	}
+}
+
+func (client *Client) ModifiedResourceHandler(oldObj, newObj interface{}) {
+	if old, ok := oldObj.(*unstructured.Unstructured); ok {
+		if updated, ok := newObj.(*unstructured.Unstructured); ok && old.GetResourceVersion() == updated.GetResourceVersion() {
+			return
+		}
+	}
+
+	client.EnqueueResource(newObj)
+}
+
+// EnqueueResource adds every ResourceSyncRule that syncs or owns the given resource to the work queue.
+func (client *Client) EnqueueResource(obj interface{}) {
+	resource, ok := objectFromTombstone(obj).(*unstructured.Unstructured)
+	if !ok {
+		log.Error("failed to cast resource")
+		return
+	}
+
+	if IsManagedBy(resource) {
+		for _, name := range ownerRuleNames(resource, clientset.ResourceSyncRule) {
+			client.Queue.Add(resourceSyncRuleKeyPrefix + name)
+		}
+		return
+	}
+
+	gvk := resource.GroupVersionKind()
+	client.enqueueMatchingResourceRules(func(rule *typesv1.ResourceSyncRule) bool {
+		return rule.ShouldSyncResource(gvk, resource.GetNamespace(), resource.GetName())
+	})
+}
+
+// ResourceMapping resolves the given kind to its resource through the RESTMapper,
+// rediscovering the API server resources once if the kind is unknown.
+func (client *Client) ResourceMapping(gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	mapping, err := client.RESTMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		if resettable, ok := client.RESTMapper.(meta.ResettableRESTMapper); ok {
+			resettable.Reset()
+			mapping, err = client.RESTMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return nil, fmt.Errorf("%s: %w", gvk.Kind, constants.ErrResourceNotNamespaced)
+	}
+
+	return mapping, nil
+}
+
+// ResourceInformer returns the informer for the given resource, starting it on first use and waiting for its cache to sync.
+// Once the informer of a kind failed to sync, it is not waited for again until its backoff has passed,
+// so that a kind the controller cannot list only blocks the worker once per backoff instead of on every sync.
+func (client *Client) ResourceInformer(gvr schema.GroupVersionResource) (informers.GenericInformer, error) {
+	client.resourceInformersLock.Lock()
+	informer, ok := client.resourceInformers[gvr]
+	if !ok {
+		informer = client.DynamicInformerFactory.ForResource(gvr)
+		informer.Informer().AddEventHandler(client.ResourceEventHandler())
+		client.DynamicInformerFactory.Start(client.Context.Done())
+		client.resourceInformers[gvr] = informer
+	}
+	failure, failed := client.resourceInformerFailures[gvr]
+	client.resourceInformersLock.Unlock()
+
+	if !informer.Informer().HasSynced() && failed && time.Now().Before(failure.retryAt) {
+		return nil, fmt.Errorf("%s: %w, retrying in %s", gvr.String(), constants.ErrCacheSync, time.Until(failure.retryAt).Round(time.Second))
+	}
+
+	ctx, cancel := context.WithTimeout(client.Context, resourceCacheSyncTimeout)
+	defer cancel()
+
+	synced := cache.WaitForCacheSync(ctx.Done(), informer.Informer().HasSynced)
+
+	client.resourceInformersLock.Lock()
+	defer client.resourceInformersLock.Unlock()
+
+	if synced {
+		delete(client.resourceInformerFailures, gvr)
+		return informer, nil
+	}
+
+	failure = client.resourceInformerFailures[gvr]
+	failure.backoff *= 2
+	if failure.backoff == 0 {
+		failure.backoff = resourceCacheSyncTimeout
+	}
+	if failure.backoff > maxResourceInformerBackoff {
+		failure.backoff = maxResourceInformerBackoff
+	}
+	failure.retryAt = time.Now().Add(failure.backoff)
+
+	if client.resourceInformerFailures == nil {
+		client.resourceInformerFailures = make(map[schema.GroupVersionResource]resourceInformerFailure)
+	}
+	client.resourceInformerFailures[gvr] = failure
+
+	return nil, fmt.Errorf("%s: %w", gvr.String(), constants.ErrCacheSync)
+}
+
+// startedResourceInformers returns the resource informers started so far.
+func (client *Client) startedResourceInformers() map[schema.GroupVersionResource]informers.GenericInformer {
+	client.resourceInformersLock.Lock()
+	defer client.resourceInformersLock.Unlock()
+
+	started := make(map[schema.GroupVersionResource]informers.GenericInformer, len(client.resourceInformers))
+	for gvr, informer := range client.resourceInformers {
+		started[gvr] = informer
+	}
+
+	return started
+}
+
+// SyncResource creates or updates the copy of the given resource in the given Namespace and returns the action taken.
+func (client *Client) SyncResource(rule *typesv1.ResourceSyncRule, gvr schema.GroupVersionResource, lister cache.GenericLister, namespace *v1.Namespace, resource *unstructured.Unstructured) (SyncAction, error) {
+	prepared := PrepareResource(rule, namespace.Name, resource)
+	logger := resourceLogger(prepared)
+
+	if obj, err := lister.ByNamespace(namespace.Name).Get(resource.GetName()); err == nil {
+		logger.Debugf("already exists")
+
+		existing, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return SyncActionNone, fmt.Errorf("unexpected cached object %T", obj)
+		}
+
+		if !rule.Spec.Rules.Force && !IsManagedBy(existing) {
+			logger.Debugf("existing resource is not managed and will not be force updated")
+			return SyncActionSkipped, nil
+		}
+
+		if IsManagedBy(existing) && client.ResourceIsUpToDate(existing, prepared) &&
+			ResourceOwnerReferencesAreValid(existing.GetOwnerReferences(), rule) {
+			logger.Debugf("existing resource contains same data")
+			return SyncActionNone, nil
+		}
+
+		prepared.SetResourceVersion(existing.GetResourceVersion())
+		return SyncActionUpdated, client.UpdateResource(gvr, prepared)
+	}
+
+	return SyncActionCreated, client.CreateResource(gvr, prepared)
+}
+
+// PruneOwnedResources deletes every resource synced by the ResourceSyncRule with the given name from all started resource informers
+// that is not part of the desired set of synced resources of the given kind. Copies of other kinds of rules with the same name are left alone.
+func (client *Client) PruneOwnedResources(ruleName string, rules typesv1.ResourceRules, gvr schema.GroupVersionResource, desired map[types.NamespacedName]bool, summary *SyncSummary) error {
+	selector := labels.SelectorFromSet(labels.Set{constants.RuleLabelKey: ruleName})
+
+	var errs []error
+	for resourceGVR, informer := range client.startedResourceInformers() {
+		objs, err := informer.Lister().List(selector)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, obj := range objs {
+			resource, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+
+			if !sets.NewString(syncedByRuleNames(resource, clientset.ResourceSyncRule)...).Has(ruleName) {
+				continue
+			}
+
+			if resourceGVR == gvr && desired[types.NamespacedName{Namespace: resource.GetNamespace(), Name: resource.GetName()}] {
+				continue
+			}
+
+			if !rules.Force && !IsManagedBy(resource) {
+				resourceLogger(resource).Debugf("existing resource is not managed and will not be force deleted")
+				continue
+			}
+
+			if err := client.DeleteResource(resourceGVR, resource); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			summary.Add(SyncActionDeleted)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (client *Client) CreateResource(gvr schema.GroupVersionResource, resource *unstructured.Unstructured) error {
+	logger := resourceLogger(resource)
+	logger.Infof("creating resource")
+
+	created, err := client.DynamicClientset.Resource(gvr).Namespace(resource.GetNamespace()).Create(client.Context, resource, metav1.CreateOptions{})
+	if err != nil {
+		logger.Errorf("failed to create resource - %s", err.Error())
+		return err
+	}
+
+	client.verifiedCopies.record(created)
+	return nil
+}
+
+func (client *Client) UpdateResource(gvr schema.GroupVersionResource, resource *unstructured.Unstructured) error {
+	logger := resourceLogger(resource)
+	logger.Infof("updating resource")
+
+	updated, err := client.DynamicClientset.Resource(gvr).Namespace(resource.GetNamespace()).Update(client.Context, resource, metav1.UpdateOptions{})
+	if err != nil {
+		logger.Errorf("failed to update resource - %s", err.Error())
+		return err
+	}
+
+	client.verifiedCopies.record(updated)
+	return nil
+}
+
+func (client *Client) DeleteResource(gvr schema.GroupVersionResource, resource *unstructured.Unstructured) error {
+	logger := resourceLogger(resource)
+	logger.Infof("deleting resource")
+
+	client.verifiedCopies.forget(resource)
+
+	err := client.DynamicClientset.Resource(gvr).Namespace(resource.GetNamespace()).Delete(client.Context, resource.GetName(), metav1.DeleteOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		logger.Errorf("failed to delete resource - %s", err.Error())
+	}
+
+	return err
+}
+
+// ResourcesAreEqual compares a prepared resource against an existing copy.
+// Fields missing from the prepared resource are ignored, since the API server may default them on the existing copy.
+func ResourcesAreEqual(prepared, existing *unstructured.Unstructured) bool {
+	for key, value := range prepared.Object {
+		if key == "metadata" {
+			continue
+		}
+		if !isSubset(value, existing.Object[key]) {
+			return false
+		}
+	}
+
+	return LabelsAreEqual(prepared.GetLabels(), existing.GetLabels(), nil) &&
+		AnnotationsAreEqual(prepared.GetAnnotations(), existing.GetAnnotations(), nil)
+}
+
+// ResourceContentHash returns a hash of the content of the given resource that is synced to its copies:
+// every field outside its metadata and the labels and annotations not set by kube-secret-sync.
+func ResourceContentHash(resource *unstructured.Unstructured) string {
+	content := make(map[string]interface{}, len(resource.Object))
+	for key, value := range resource.Object {
+		if key != "metadata" {
+			content[key] = value
+		}
+	}
+
+	return contentHash(struct {
+		Content     map[string]interface{} `json:"content"`
+		Labels      map[string]string      `json:"labels"`
+		Annotations map[string]string      `json:"annotations"`
+	}{
+		Content:     content,
+		Labels:      CopyLabels(resource.GetLabels()),
+		Annotations: CopyAnnotations(resource.GetAnnotations()),
+	})
+}
+
+// ResourceIsUpToDate determines whether or not the given synced copy was written from the given prepared copy.
+// Copies whose recorded hash cannot be trusted are compared with ResourcesAreEqual,
+// since the API server may have defaulted fields that change their hash.
+func (client *Client) ResourceIsUpToDate(existing, prepared *unstructured.Unstructured) bool {
+	hash := prepared.GetAnnotations()[constants.ContentHashAnnotationKey]
+	return client.CopyIsUpToDate(existing, hash, func() bool { return ResourcesAreEqual(prepared, existing) })
+}
+
+// isSubset reports whether every field of desired is set to the same value in actual.
+func isSubset(desired, actual interface{}) bool {
+	switch desired := desired.(type) {
+	case map[string]interface{}:
+		actual, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range desired {
+			if !isSubset(value, actual[key]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		actual, ok := actual.([]interface{})
+		if !ok || len(desired) != len(actual) {
+			return false
+		}
+		for i := range desired {
+			if !isSubset(desired[i], actual[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return reflect.DeepEqual(desired, actual)
+}
+
+// PrepareResource builds the copy of the given source resource to write to the given Namespace,
+// stripping its status and every server managed metadata field.
+func PrepareResource(rule *typesv1.ResourceSyncRule, namespace string, resource *unstructured.Unstructured) *unstructured.Unstructured {
+	prepared := resource.DeepCopy()
+
+	unstructured.RemoveNestedField(prepared.Object, "status")
+	for _, field := range serverManagedFields {
+		unstructured.RemoveNestedField(prepared.Object, "metadata", field)
+	}
+
+	prepared.SetNamespace(namespace)
+	prepared.SetLabels(LabelRule(CopyLabels(resource.GetLabels()), clientset.ResourceSyncRule, rule))
+	prepared.SetOwnerReferences([]metav1.OwnerReference{ResourceOwnerReference(rule)})
+
+	annotations := AnnotateProvenance(Manage(CopyAnnotations(resource.GetAnnotations())), rule.Name, resource)
+	prepared.SetAnnotations(annotations)
+	annotations[constants.ContentHashAnnotationKey] = ResourceContentHash(prepared)
+	prepared.SetAnnotations(annotations)
+
+	return prepared
+}
@@ -0,0 +1,178 @@
+package client
+
+import (
+	"fmt"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/constants"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/cache"
+)
+
+func (client *Client) ResourceSyncRuleEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    client.AddedResourceSyncRuleHandler,
+		UpdateFunc: client.ModifiedResourceSyncRuleHandler,
+		DeleteFunc: client.DeletedResourceSyncRuleHandler,
+	}
+}
+
+func (client *Client) AddedResourceSyncRuleHandler(obj interface{}) {
+	rule, ok := obj.(*typesv1.ResourceSyncRule)
+	if !ok {
+		log.Error("failed to cast ResourceSyncRule")
+		return
+	}
+
+	resourceRuleLogger(rule).Infof("added")
+	client.EnqueueResourceSyncRule(rule)
+}
+
+// ModifiedResourceSyncRuleHandler handles syncing resources after a ResourceSyncRule has been modified,
+// the same way ModifiedSecretSyncRuleHandler does for SecretSyncRules.
+func (client *Client) ModifiedResourceSyncRuleHandler(oldObj, newObj interface{}) {
+	rule, ok := newObj.(*typesv1.ResourceSyncRule)
+	if !ok {
+		log.Error("failed to cast ResourceSyncRule")
+		return
+	}
+
+	if old, ok := oldObj.(*typesv1.ResourceSyncRule); ok {
+		if old.ResourceVersion == rule.ResourceVersion {
+			resourceRuleLogger(rule).Debugf("periodic resync")
+			client.EnqueueResourceSyncRule(rule)
+			return
+		}
+
+		if old.Generation == rule.Generation && old.DeletionTimestamp.Equal(rule.DeletionTimestamp) {
+			resourceRuleLogger(rule).Debugf("status or metadata updated")
+			return
+		}
+	}
+
+	resourceRuleLogger(rule).Infof("modified")
+	client.EnqueueResourceSyncRule(rule)
+}
+
+func (client *Client) DeletedResourceSyncRuleHandler(obj interface{}) {
+	rule, ok := objectFromTombstone(obj).(*typesv1.ResourceSyncRule)
+	if !ok {
+		log.Error("failed to cast ResourceSyncRule")
+		return
+	}
+
+	resourceRuleLogger(rule).Infof("deleted")
+	client.EnqueueResourceSyncRule(rule)
+}
+
+// SyncResourceSyncRule reconciles the ResourceSyncRule with the given name against the informer caches.
+// Like SyncSecretSyncRule, it is safe to call repeatedly for the same name.
+func (client *Client) SyncResourceSyncRule(name string) error {
+	rule, err := client.ResourceSyncRuleLister.Get(name)
+	if errors.IsNotFound(err) {
+		logger := resourceRuleNameLogger(name)
+		logger.Debugf("no longer exists, removing synced resources")
+
+		summary := new(SyncSummary)
+		err := client.PruneOwnedResources(name, typesv1.ResourceRules{}, schema.GroupVersionResource{}, nil, summary)
+		summary.Log(logger)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if rule.DeletionTimestamp != nil {
+		return nil
+	}
+
+	logger := resourceRuleLogger(rule)
+	logger.Debugf("syncing")
+
+	summary := new(SyncSummary)
+	err = client.syncResourceSyncRule(rule, summary)
+	summary.Log(logger)
+
+	if IsRuleError(err) {
+		err = nil
+	}
+
+	return utilerrors.NewAggregate([]error{err, client.UpdateResourceSyncRuleStatus(rule, summary)})
+}
+
+func (client *Client) syncResourceSyncRule(rule *typesv1.ResourceSyncRule, summary *SyncSummary) error {
+	logger := resourceRuleLogger(rule)
+	reference := rule.Spec.Resource
+
+	mapping, err := client.ResourceMapping(reference.GroupVersionKind())
+	if err != nil {
+		logger.Debugf("failed to resolve kind %s: %s", reference.Kind, err.Error())
+		summary.InvalidSource = err.Error()
+		return err
+	}
+
+	informer, err := client.ResourceInformer(mapping.Resource)
+	if err != nil {
+		summary.InvalidSource = err.Error()
+		return err
+	}
+	lister := informer.Lister()
+
+	var resource *unstructured.Unstructured
+	obj, err := lister.ByNamespace(reference.Namespace).Get(reference.Name)
+	switch {
+	case errors.IsNotFound(err):
+		logger.Debugf("source %s %s/%s does not exist", reference.Kind, reference.Namespace, reference.Name)
+		summary.MissingSources = append(summary.MissingSources, reference.Namespace+"/"+reference.Name)
+	case err != nil:
+		return err
+	default:
+		resource, _ = obj.(*unstructured.Unstructured)
+	}
+
+	var errs []error
+	desired := make(map[types.NamespacedName]bool)
+
+	namespaces := rule.Namespaces(client.NamespaceLister)
+	summary.Targets = len(namespaces)
+	summary.Refused(rule.RefusedNamespaces(client.NamespaceLister))
+
+	if resource != nil {
+		summary.Sources = 1
+
+		for _, namespace := range namespaces {
+			desired[types.NamespacedName{Namespace: namespace.Name, Name: resource.GetName()}] = true
+
+			action, err := client.SyncResource(rule, mapping.Resource, lister, namespace, resource)
+			switch {
+			case err != nil:
+				errs = append(errs, err)
+				summary.Failed(namespace.Name, "", err)
+			case action == SyncActionSkipped:
+				summary.Failed(namespace.Name, ReasonResourceNotManaged, fmt.Errorf("%s: %w", resource.GetName(), constants.ErrResourceNotManaged))
+			default:
+				summary.Succeeded(namespace.Name, action)
+			}
+		}
+	}
+
+	if err := client.PruneOwnedResources(rule.Name, rule.Spec.Rules, mapping.Resource, desired, summary); err != nil {
+		errs = append(errs, err)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (client *Client) ListResourceSyncRules() (rules []*typesv1.ResourceSyncRule, err error) {
+	rules, err = client.ResourceSyncRuleLister.List(labels.Everything())
+	if err != nil {
+		log.Errorf("failed to list ResourceSyncRules: %s", err.Error())
+	}
+	return
+}
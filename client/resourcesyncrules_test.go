@@ -0,0 +1,155 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/alehechka/kube-secret-sync/api/types"
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
+	pkg "github.com/alehechka/kube-secret-sync/client"
+	"github.com/alehechka/kube-secret-sync/constants"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_SyncResourceSyncRule(t *testing.T) {
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultNetworkPolicy, testResourceSyncRule)
+
+	err := client.SyncResourceSyncRule(keyTestResourceSyncRule)
+	assert.NoError(t, err)
+
+	resource, err := client.DynamicClientset.Resource(networkPolicyResource).Namespace(keyTestNamespace).Get(client.Context, keyDefaultNetworkPolicy, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, pkg.IsManagedBy(resource))
+	assert.Empty(t, resource.GetUID())
+	assert.Equal(t, defaultNetworkPolicy.Object["spec"], resource.Object["spec"])
+	assert.NotContains(t, resource.Object, "status")
+	assert.Equal(t, keyTestResourceSyncRule, resource.GetOwnerReferences()[0].Name)
+
+	rule, err := client.KubeSecretSyncClientset.ResourceSyncRules().Get(client.Context, keyTestResourceSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, meta.IsStatusConditionTrue(rule.Status.Conditions, typesv1.ConditionReady))
+}
+
+func Test_SyncResourceSyncRule_Unmanaged(t *testing.T) {
+	unmanaged := defaultNetworkPolicy.DeepCopy()
+	unmanaged.SetNamespace(keyTestNamespace)
+	unmanaged.Object["spec"] = map[string]interface{}{"podSelector": map[string]interface{}{}}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultNetworkPolicy, unmanaged, testResourceSyncRule)
+
+	err := client.SyncResourceSyncRule(keyTestResourceSyncRule)
+	assert.NoError(t, err)
+
+	resource, err := client.DynamicClientset.Resource(networkPolicyResource).Namespace(keyTestNamespace).Get(client.Context, keyDefaultNetworkPolicy, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.False(t, pkg.IsManagedBy(resource))
+
+	rule, err := client.KubeSecretSyncClientset.ResourceSyncRules().Get(client.Context, keyTestResourceSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.ReasonResourceNotManaged, rule.Status.Failures[0].Reason)
+}
+
+func Test_SyncResourceSyncRule_KeepsOtherRuleKindCopies(t *testing.T) {
+	excluded := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: keyExcludedNamespace}}
+
+	rule := testResourceSyncRule.DeepCopy()
+	rule.Spec.Rules.Namespaces.Exclude = types.StringSlice{keyExcludedNamespace}
+
+	// a copy written by a SecretSyncRule with the same name as the ResourceSyncRule
+	other := pkg.PrepareResource(rule, keyExcludedNamespace, defaultNetworkPolicy)
+	labels := other.GetLabels()
+	labels[constants.RuleKindLabelKey] = clientset.SecretSyncRule
+	other.SetLabels(labels)
+	other.SetOwnerReferences(nil)
+
+	// a stale copy of the ResourceSyncRule itself that predates the rule kind label
+	stale := pkg.PrepareResource(rule, keyExcludedNamespace, defaultNetworkPolicy)
+	stale.SetName("stale")
+	labels = stale.GetLabels()
+	delete(labels, constants.RuleKindLabelKey)
+	stale.SetLabels(labels)
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, excluded, defaultNetworkPolicy, other, stale, rule)
+
+	err := client.SyncResourceSyncRule(keyTestResourceSyncRule)
+	assert.NoError(t, err)
+
+	_, err = client.DynamicClientset.Resource(networkPolicyResource).Namespace(keyExcludedNamespace).Get(client.Context, keyDefaultNetworkPolicy, metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	_, err = client.DynamicClientset.Resource(networkPolicyResource).Namespace(keyExcludedNamespace).Get(client.Context, "stale", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func Test_SyncResourceSyncRule_NotNamespaced(t *testing.T) {
+	rule := testResourceSyncRule.DeepCopy()
+	rule.Spec.Resource = typesv1.Resource{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Name: "view"}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, rule)
+
+	err := client.SyncResourceSyncRule(keyTestResourceSyncRule)
+	assert.NoError(t, err)
+
+	rule, err = client.KubeSecretSyncClientset.ResourceSyncRules().Get(client.Context, keyTestResourceSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	condition := meta.FindStatusCondition(rule.Status.Conditions, typesv1.ConditionSourceFound)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Contains(t, condition.Message, "not namespaced")
+}
+
+func Test_SyncResourceSyncRule_MissingSource(t *testing.T) {
+	client := InitializeTestClientset(defaultNamespace, testNamespace, testResourceSyncRule)
+
+	err := client.SyncResourceSyncRule(keyTestResourceSyncRule)
+	assert.NoError(t, err)
+
+	rule, err := client.KubeSecretSyncClientset.ResourceSyncRules().Get(client.Context, keyTestResourceSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.False(t, meta.IsStatusConditionTrue(rule.Status.Conditions, typesv1.ConditionSourceFound))
+}
+
+func Test_PrepareResource_StripsServerManagedFields(t *testing.T) {
+	source := defaultNetworkPolicy.DeepCopy()
+	source.SetOwnerReferences([]metav1.OwnerReference{{Kind: "Deployment", Name: "owner"}})
+	source.SetFinalizers([]string{"example.com/finalizer"})
+
+	prepared := pkg.PrepareResource(testResourceSyncRule, keyTestNamespace, source)
+
+	assert.Equal(t, keyTestNamespace, prepared.GetNamespace())
+	assert.Empty(t, prepared.GetUID())
+	assert.Empty(t, prepared.GetResourceVersion())
+	assert.Empty(t, prepared.GetFinalizers())
+	assert.NotContains(t, prepared.Object, "status")
+	assert.Len(t, prepared.GetOwnerReferences(), 1)
+	assert.Equal(t, keyTestResourceSyncRule, prepared.GetOwnerReferences()[0].Name)
+	assert.Equal(t, "42", source.GetResourceVersion())
+}
+
+func Test_ResourcesAreEqual_IgnoresDefaultedFields(t *testing.T) {
+	prepared := pkg.PrepareResource(testResourceSyncRule, keyTestNamespace, defaultNetworkPolicy)
+
+	existing := prepared.DeepCopy()
+	existing.SetResourceVersion("7")
+	assert.NoError(t, unstructured.SetNestedField(existing.Object, "default", "spec", "defaulted"))
+	assert.True(t, pkg.ResourcesAreEqual(prepared, existing))
+
+	assert.NoError(t, unstructured.SetNestedStringSlice(existing.Object, []string{"Egress"}, "spec", "policyTypes"))
+	assert.False(t, pkg.ResourcesAreEqual(prepared, existing))
+}
+
+func Test_PrepareResource_Provenance(t *testing.T) {
+	prepared := pkg.PrepareResource(testResourceSyncRule, keyTestNamespace, defaultNetworkPolicy)
+	annotations := prepared.GetAnnotations()
+
+	assert.Equal(t, defaultNetworkPolicy.GetNamespace(), annotations[constants.SourceNamespaceAnnotationKey])
+	assert.Equal(t, defaultNetworkPolicy.GetName(), annotations[constants.SourceNameAnnotationKey])
+	assert.Equal(t, "42", annotations[constants.SourceResourceVersionAnnotationKey])
+	assert.Equal(t, keyTestResourceSyncRule, annotations[constants.RuleAnnotationKey])
+	assert.Equal(t, pkg.ResourceContentHash(prepared), annotations[constants.ContentHashAnnotationKey])
+}
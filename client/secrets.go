@@ -1,159 +1,368 @@
 package client
 
 import (
-	"reflect"
-
 	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
 	"github.com/alehechka/kube-secret-sync/constants"
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/cache"
 )
 
-func (client *Client) SecretEventHandler(event watch.Event) error {
-	secret, ok := event.Object.(*v1.Secret)
+func (client *Client) SecretEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    client.AddedSecretHandler,
+		UpdateFunc: client.ModifiedSecretHandler,
+		DeleteFunc: client.DeletedSecretHandler,
+	}
+}
+
+func (client *Client) AddedSecretHandler(obj interface{}) {
+	secret, ok := obj.(*v1.Secret)
 	if !ok {
 		log.Error("failed to cast Secret")
-		return nil
+		return
 	}
 
 	if IsManagedBy(secret) {
-		return nil
+		return
 	}
 
-	switch event.Type {
-	case watch.Added:
-		return client.AddedSecretHandler(secret)
-	case watch.Modified:
-		return client.ModifiedSecretHandler(secret)
-	case watch.Deleted:
-		return client.DeletedSecretHandler(secret)
+	if secret.CreationTimestamp.Time.Before(client.StartTime) {
+		secretLogger(secret).Debugf("secret will be synced on startup by SecretSyncRule informer")
+		return
 	}
 
-	return nil
+	client.EnqueueSecret(secret, "added")
 }
 
-func (client *Client) AddedSecretHandler(secret *v1.Secret) error {
-	logger := secretLogger(secret)
+func (client *Client) ModifiedSecretHandler(oldObj, newObj interface{}) {
+	secret, ok := newObj.(*v1.Secret)
+	if !ok {
+		log.Error("failed to cast Secret")
+		return
+	}
 
-	if secret.CreationTimestamp.Time.Before(client.StartTime) {
-		logger.Debugf("secret will be synced on startup by SecretSyncRule watcher")
-		return nil
+	if secret.DeletionTimestamp != nil {
+		return
 	}
 
-	logger.Infof("added")
-	return client.SyncAddedModifiedSecret(secret)
-}
+	if old, ok := oldObj.(*v1.Secret); ok && old.ResourceVersion == secret.ResourceVersion {
+		return
+	}
 
-func (client *Client) ModifiedSecretHandler(secret *v1.Secret) error {
-	if secret.DeletionTimestamp != nil {
-		return nil
+	if IsManagedBy(secret) {
+		client.EnqueueDriftedSecret(secret, false)
+		return
 	}
 
-	secretLogger(secret).Infof("modified")
-	return client.SyncAddedModifiedSecret(secret)
+	if old, ok := oldObj.(*v1.Secret); ok {
+		client.EnqueueSecret(secret, "modified", old)
+		return
+	}
+
+	client.EnqueueSecret(secret, "modified")
 }
 
-func (client *Client) SyncAddedModifiedSecret(secret *v1.Secret) error {
-	rules, err := client.ListSecretSyncRules()
-	if err != nil {
-		return err
+func (client *Client) DeletedSecretHandler(obj interface{}) {
+	secret, ok := objectFromTombstone(obj).(*v1.Secret)
+	if !ok {
+		log.Error("failed to cast Secret")
+		return
+	}
+
+	if IsManagedBy(secret) {
+		client.verifiedCopies.forget(secret)
+		client.EnqueueDriftedSecret(secret, true)
+		return
 	}
 
-	for _, rule := range rules.Items {
-		if rule.ShouldSyncSecret(secret) {
-			for _, namespace := range rule.Namespaces(client.Context, client.DefaultClientset) {
-				client.CreateUpdateSecret(&rule, &namespace, secret)
+	client.EnqueueSecret(secret, "deleted")
+}
+
+// EnqueueSecret adds every SecretSyncRule that syncs the given Secret to the work queue.
+// Rules that synced any of the given previous states of the Secret are queued as well, so that copies of a Secret
+// that stopped matching a rule's secretSelector get pruned, and so are rules reading the kubeconfig of a remote cluster from it.
+func (client *Client) EnqueueSecret(secret *v1.Secret, event string, previous ...*v1.Secret) {
+	matches := func(rule *typesv1.SecretSyncRule) bool {
+		if rule.ShouldSyncSecret(secret) || rule.UsesKubeconfig(secret) {
+			return true
+		}
+		for _, old := range previous {
+			if rule.ShouldSyncSecret(old) {
+				return true
 			}
 		}
+		return false
 	}
 
-	return nil
+	if client.enqueueMatching(matches) > 0 {
+		secretLogger(secret).Infof(event)
+	}
 }
 
-func (client *Client) CreateUpdateSecret(rule *typesv1.SecretSyncRule, namespace *v1.Namespace, secret *v1.Secret) error {
-	logger := secretLogger(secret, namespace)
+// EnqueueDriftedSecret adds the SecretSyncRules owning the given managed Secret to the work queue
+// if the Secret was deleted while still targeted or no longer matches its source.
+// Writes made by the controller itself leave the copy equal to its source and are therefore ignored.
+func (client *Client) EnqueueDriftedSecret(secret *v1.Secret, deleted bool) {
+	logger := secretLogger(secret)
 
-	if namespaceSecret, err := client.GetSecret(namespace.Name, secret.Name); err == nil {
+	for _, key := range ownerRuleNames(secret, clientset.SecretSyncRule) {
+		rule, err := client.GetSecretSyncRule(key)
+		if err != nil || rule.DeletionTimestamp != nil {
+			continue
+		}
+
+		source, ok := client.SourceSecretFor(rule, secret)
+		if !ok {
+			continue
+		}
+
+		namespace, err := client.NamespaceLister.Get(secret.Namespace)
+		if err != nil {
+			continue
+		}
+
+		if deleted {
+			if !rule.ShouldSyncNamespace(namespace) {
+				continue
+			}
+			logger.Infof("deleted while still targeted by SecretSyncRule %s, restoring", rule.Name)
+		} else {
+			if prepared, err := PrepareSecret(rule, namespace, source); err == nil && client.SecretIsUpToDate(secret, prepared, &rule.Spec.Rules) {
+				continue
+			}
+			logger.Infof("drifted from source of SecretSyncRule %s, restoring", rule.Name)
+		}
+
+		client.Enqueue(rule)
+	}
+}
+
+// SyncSecret creates or updates the copy of the given Secret in the given Namespace and returns the action taken.
+func (client *Client) SyncSecret(rule *typesv1.SecretSyncRule, namespace *v1.Namespace, secret *v1.Secret) (SyncAction, error) {
+	prepared, err := PrepareSecret(rule, namespace, secret)
+	if err != nil {
+		ruleLogger(rule).Errorf("failed to prepare secret: %s", err.Error())
+		return SyncActionNone, err
+	}
+
+	logger := secretLogger(prepared)
+
+	if namespaceSecret, err := client.SecretLister.Secrets(namespace.Name).Get(prepared.Name); err == nil {
 		logger.Debugf("already exists")
 
-		if !rule.Spec.Rules.Force && !IsManagedBy(namespaceSecret) {
-			logger.Debugf("existing secret is not managed and will not be force updated")
-			return nil
+		if !IsManagedBy(namespaceSecret) {
+			policy := rule.Spec.Rules.OnConflict()
+			action, resolved := ResolveConflict(policy, prepared, namespaceSecret)
+			logConflict(logger, policy, action)
+
+			if resolved == nil {
+				return action, nil
+			}
+			_, err := client.DefaultClientset.CoreV1().Secrets(namespace.Name).Update(client.Context, resolved, metav1.UpdateOptions{})
+			if err != nil {
+				logger.Errorf("failed to update secret - %s", err.Error())
+			}
+			return action, err
+		}
+
+		if client.SyncedByOtherExistingRule(namespaceSecret, rule) {
+			logger.Warnf("existing secret is managed by another rule and was left untouched")
+			return SyncActionConflict, nil
 		}
 
-		if IsManagedBy(namespaceSecret) && SecretsAreEqual(secret, namespaceSecret) {
+		if client.SecretIsUpToDate(namespaceSecret, prepared, &rule.Spec.Rules) &&
+			OwnerReferencesAreValid(namespaceSecret.OwnerReferences, rule) {
 			logger.Debugf("existing secret contains same data")
-			return nil
+			return SyncActionNone, nil
+		}
+
+		return SyncActionUpdated, client.UpdateSecret(rule, namespace, secret)
+	}
+
+	return SyncActionCreated, client.CreateSecret(rule, namespace, secret)
+}
+
+// SyncedByOtherExistingRule determines whether or not the given managed Secret was synced by a rule other than the given SecretSyncRule
+// that still exists. Labelled copies left behind by a deleted rule, such as the ones it retained, are taken over by the given rule.
+func (client *Client) SyncedByOtherExistingRule(secret *v1.Secret, rule *typesv1.SecretSyncRule) bool {
+	if !SyncedByOtherRule(secret, rule) {
+		return false
+	}
+
+	if _, ok := secret.Labels[constants.RuleLabelKey]; !ok || syncedByRuleKind(secret, clientset.SecretSyncRule) != clientset.SecretSyncRule {
+		return true
+	}
+
+	for _, key := range syncedByRuleNames(secret, clientset.SecretSyncRule) {
+		if key == ruleKey(rule) {
+			continue
+		}
+
+		namespace, name, err := cache.SplitMetaNamespaceKey(key)
+		if err != nil {
+			return true
 		}
 
-		return client.UpdateSecret(rule, namespace, secret)
+		if namespace != "" {
+			_, err = client.NamespacedSecretSyncRuleLister.Get(namespace, name)
+		} else {
+			_, err = client.SecretSyncRuleLister.Get(name)
+		}
+		if !errors.IsNotFound(err) {
+			return true
+		}
 	}
 
-	return client.CreateSecret(rule, namespace, secret)
+	return false
 }
 
-func (client *Client) DeletedSecretHandler(secret *v1.Secret) error {
-	secretLogger(secret).Infof("deleted")
+// DeleteSyncedSecret deletes the given synced Secret in the given Namespace if it is managed or the rules overwrite conflicting Secrets,
+// and returns the action taken.
+func (client *Client) DeleteSyncedSecret(rules typesv1.Rules, namespace *v1.Namespace, secret *v1.Secret) (SyncAction, error) {
+	logger := secretLogger(secret, namespace)
 
-	rules, err := client.ListSecretSyncRules()
+	if namespaceSecret, err := client.SecretLister.Secrets(namespace.Name).Get(secret.Name); err == nil {
+		if rules.OnConflict() == typesv1.ConflictPolicyOverwrite || IsManagedBy(namespaceSecret) {
+			return SyncActionDeleted, client.DeleteSecret(namespace, secret)
+		}
+
+		logger.Debugf("existing secret is not managed and will not be deleted")
+		return SyncActionSkipped, nil
+	}
+
+	return SyncActionNone, nil
+}
+
+// PruneOwnedSecrets removes every Secret synced by the SecretSyncRule with the given key
+// that is not part of the desired set of synced Secrets, applying the deletion policy returned by the given function to each of them.
+// Copies that are no longer managed are only deleted when the rules overwrite conflicting Secrets.
+func (client *Client) PruneOwnedSecrets(ruleKey string, rules typesv1.Rules, desired map[types.NamespacedName]bool,
+	policy func(secret *v1.Secret) typesv1.DeletionPolicyType, summary *SyncSummary) error {
+	secrets, err := client.OwnedSecrets(ruleKey)
 	if err != nil {
 		return err
 	}
 
-	for _, rule := range rules.Items {
-		if rule.ShouldSyncSecret(secret) {
-			for _, namespace := range rule.Namespaces(client.Context, client.DefaultClientset) {
-				client.SyncDeletedSecret(rule.Spec.Rules, &namespace, secret)
-			}
+	var errs []error
+	for _, secret := range secrets {
+		if desired[types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}] {
+			continue
+		}
+
+		var action SyncAction
+		switch policy(secret) {
+		case typesv1.DeletionPolicyRetain:
+			secretLogger(secret).Debugf("retaining secret that is no longer synced")
+			continue
+		case typesv1.DeletionPolicyOrphan:
+			action, err = SyncActionOrphaned, client.OrphanSecret(secret)
+		default:
+			namespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: secret.Namespace}}
+			action, err = client.DeleteSyncedSecret(rules, namespace, secret)
 		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		summary.Add(action)
 	}
 
-	return nil
+	return utilerrors.NewAggregate(errs)
 }
 
-func (client *Client) SyncDeletedSecret(rules typesv1.Rules, namespace *v1.Namespace, secret *v1.Secret) error {
-	logger := secretLogger(secret, namespace)
+// deletePolicy deletes every synced copy that is no longer desired.
+func deletePolicy(*v1.Secret) typesv1.DeletionPolicyType {
+	return typesv1.DeletionPolicyDelete
+}
 
-	if namespaceSecret, err := client.GetSecret(namespace.Name, secret.Name); err == nil {
-		if rules.Force || IsManagedBy(namespaceSecret) {
-			return client.DeleteSecret(namespace, secret)
+// SourceDeletionPolicy returns the deletion policy of the given SecretSyncRule for a synced copy that is no longer desired.
+// Copies in namespaces that the rule still targets are no longer desired because their source disappeared,
+// either deleted or no longer matched by the rule, so the source deletion policy of the rule applies to them.
+// Copies in namespaces that the rule no longer targets are always deleted.
+func (client *Client) SourceDeletionPolicy(rule *typesv1.SecretSyncRule) func(secret *v1.Secret) typesv1.DeletionPolicyType {
+	return func(secret *v1.Secret) typesv1.DeletionPolicyType {
+		namespace, err := client.NamespaceLister.Get(secret.Namespace)
+		if err != nil || !rule.ShouldSyncNamespace(namespace) {
+			return typesv1.DeletionPolicyDelete
 		}
 
-		logger.Debugf("existing secret is not managed and will not be force deleted")
+		return rule.Spec.SourceDeletionPolicy()
 	}
+}
 
-	return nil
+// OrphanSecret releases the given synced Secret from kube-secret-sync, keeping its data.
+func (client *Client) OrphanSecret(secret *v1.Secret) error {
+	logger := secretLogger(secret)
+	logger.Infof("orphaning secret")
+
+	_, err := client.DefaultClientset.CoreV1().Secrets(secret.Namespace).Update(client.Context, Orphaned(secret), metav1.UpdateOptions{})
+	if err != nil {
+		logger.Errorf("failed to orphan secret - %s", err.Error())
+	}
+
+	return err
+}
+
+// OwnedSecrets returns all cached Secrets synced by the SecretSyncRule with the given key,
+// which is the name of a SecretSyncRule or the namespace and name of a NamespacedSecretSyncRule.
+func (client *Client) OwnedSecrets(ruleKey string) (secrets []*v1.Secret, err error) {
+	objs, err := client.SecretInformer.GetIndexer().ByIndex(ruleIndex, ruleKey)
+	if err != nil {
+		ruleNameLogger(ruleKey).Errorf("failed to list owned secrets: %s", err.Error())
+		return nil, err
+	}
+
+	for _, obj := range objs {
+		if secret, ok := obj.(*v1.Secret); ok {
+			secrets = append(secrets, secret)
+		}
+	}
+
+	return
 }
 
 func (client *Client) CreateSecret(rule *typesv1.SecretSyncRule, namespace *v1.Namespace, secret *v1.Secret) error {
-	newSecret := PrepareSecret(rule, namespace, secret)
+	newSecret, err := PrepareSecret(rule, namespace, secret)
+	if err != nil {
+		return err
+	}
 
 	logger := secretLogger(newSecret)
 	logger.Infof("creating secret")
 
-	_, err := client.DefaultClientset.CoreV1().Secrets(namespace.Name).Create(client.Context, newSecret, metav1.CreateOptions{})
-
+	created, err := client.DefaultClientset.CoreV1().Secrets(namespace.Name).Create(client.Context, newSecret, metav1.CreateOptions{})
 	if err != nil {
 		logger.Errorf("failed to create secret - %s", err.Error())
+		return err
 	}
 
-	return err
+	client.verifiedCopies.record(created)
+	return nil
 }
 
 func (client *Client) UpdateSecret(rule *typesv1.SecretSyncRule, namespace *v1.Namespace, secret *v1.Secret) (err error) {
-	updateSecret := PrepareSecret(rule, namespace, secret)
+	updateSecret, err := PrepareSecret(rule, namespace, secret)
+	if err != nil {
+		return err
+	}
 
 	logger := secretLogger(updateSecret)
 	logger.Infof("updating secret")
 
-	_, err = client.DefaultClientset.CoreV1().Secrets(namespace.Name).Update(client.Context, updateSecret, metav1.UpdateOptions{})
+	updated, err := client.DefaultClientset.CoreV1().Secrets(namespace.Name).Update(client.Context, updateSecret, metav1.UpdateOptions{})
 	if err != nil {
 		logger.Errorf("failed to update secret - %s", err.Error())
+		return err
 	}
 
-	return
+	client.verifiedCopies.record(updated)
+	return nil
 }
 
 func (client *Client) DeleteSecret(namespace *v1.Namespace, secret *v1.Secret) (err error) {
@@ -187,35 +396,99 @@ func (client *Client) ListSecrets(namespace string) (list *v1.SecretList, err er
 	return
 }
 
-func SecretsAreEqual(a, b *v1.Secret) bool {
-	return (a.Type == b.Type &&
-		reflect.DeepEqual(a.Data, b.Data) &&
-		reflect.DeepEqual(a.StringData, b.StringData) &&
-		AnnotationsAreEqual(a.Annotations, b.Annotations))
+// SecretsAreEqual compares the content hashes of the given Secrets, computed from their content rather than
+// taken from the hashes recorded on synced copies, which may be stale. If rules are given, labels and annotations
+// that they neither copy nor add are ignored.
+func SecretsAreEqual(a, b *v1.Secret, rules *typesv1.Rules) bool {
+	return ContentHash(a, rules) == ContentHash(b, rules)
+}
+
+// ContentHash returns a hash of the content of the given Secret that is synced to its copies:
+// its type, data and the labels and annotations that the given rules copy or add.
+// Annotations marking a copy as managed and recording its provenance are left out.
+func ContentHash(secret *v1.Secret, rules *typesv1.Rules) string {
+	var labels, annotations *typesv1.MetadataRules
+	if rules != nil {
+		labels, annotations = &rules.Labels, &rules.Annotations
+	}
+
+	return contentHash(struct {
+		Type        v1.SecretType     `json:"type"`
+		Data        map[string][]byte `json:"data"`
+		StringData  map[string]string `json:"stringData"`
+		Labels      map[string]string `json:"labels"`
+		Annotations map[string]string `json:"annotations"`
+	}{
+		Type:        secret.Type,
+		Data:        secret.Data,
+		StringData:  secret.StringData,
+		Labels:      filterMetadata(CopyLabels(secret.Labels), labels),
+		Annotations: filterMetadata(CopyAnnotations(secret.Annotations), annotations, constants.DeletionPolicyAnnotationKey),
+	})
+}
+
+// SecretIsUpToDate determines whether or not the given synced copy was written from the given prepared copy.
+func (client *Client) SecretIsUpToDate(existing, prepared *v1.Secret, rules *typesv1.Rules) bool {
+	hash := prepared.Annotations[constants.ContentHashAnnotationKey]
+	return client.CopyIsUpToDate(existing, hash, func() bool { return ContentHash(existing, rules) == hash })
 }
 
-func PrepareSecret(rule *typesv1.SecretSyncRule, namespace *v1.Namespace, secret *v1.Secret) *v1.Secret {
-	annotations := Manage(CopyAnnotations(secret.Annotations))
+// PrepareSecret builds the copy of the given source Secret to write to the given Namespace.
+func PrepareSecret(rule *typesv1.SecretSyncRule, namespace *v1.Namespace, secret *v1.Secret) (*v1.Secret, error) {
+	name, err := rule.TargetName(secret, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := rule.RenderTemplate(secret, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	data := TransformData(&rule.Spec.Rules.Keys, secret.Data)
+	if len(rendered) > 0 {
+		// the transformed data may be the cached data of the source itself, so the rendered keys are merged into a new map
+		merged := make(map[string][]byte, len(data)+len(rendered))
+		for key, value := range data {
+			merged[key] = value
+		}
+		for key, value := range rendered {
+			merged[key] = value
+		}
+		data = merged
+	}
+
+	kind := clientset.SecretSyncRule
+	if rule.Namespace != "" {
+		kind = clientset.NamespacedSecretSyncRule
+	}
+
+	labels := LabelRule(CopyLabels(CopyMetadata(secret.Labels, &rule.Spec.Rules.Labels)), kind, rule)
+	annotations := AnnotateDeletionPolicy(Manage(CopyAnnotations(CopyMetadata(secret.Annotations, &rule.Spec.Rules.Annotations))), rule)
+	annotations = AnnotateProvenance(annotations, ruleKey(rule), secret)
 
-	return &v1.Secret{
+	prepared := &v1.Secret{
 		TypeMeta: secret.TypeMeta,
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            secret.Name,
+			Name:            name,
 			Namespace:       namespace.Name,
-			Labels:          secret.Labels,
+			Labels:          labels,
 			Annotations:     annotations,
-			OwnerReferences: []metav1.OwnerReference{OwnerReference(rule)},
+			OwnerReferences: OwnerReferences(rule),
 		},
 		Immutable:  secret.Immutable,
-		Data:       secret.Data,
-		StringData: secret.StringData,
+		Data:       data,
+		StringData: TransformData(&rule.Spec.Rules.Keys, secret.StringData),
 		Type:       secret.Type,
 	}
+	prepared.Annotations[constants.ContentHashAnnotationKey] = ContentHash(prepared, &rule.Spec.Rules)
 
+	return prepared, nil
 }
 
-func IsManagedBy(secret *v1.Secret) bool {
-	managedBy, ok := secret.Annotations[constants.ManagedByAnnotationKey]
+// IsManagedBy determines whether or not the given object is managed by kube-secret-sync
+func IsManagedBy(obj metav1.Object) bool {
+	managedBy, ok := obj.GetAnnotations()[constants.ManagedByAnnotationKey]
 
 	return ok && managedBy == constants.ManagedByAnnotationValue
 }
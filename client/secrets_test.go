@@ -3,6 +3,9 @@ package client_test
 import (
 	"testing"
 
+	"github.com/alehechka/kube-secret-sync/api/types"
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/api/types/v1/clientset"
 	pkg "github.com/alehechka/kube-secret-sync/client"
 	"github.com/alehechka/kube-secret-sync/constants"
 	"github.com/stretchr/testify/assert"
@@ -96,11 +99,11 @@ func Test_CreateSecret(t *testing.T) {
 	secret, err := client.GetSecret(keyDefault, keyDefaultSecret)
 	assert.NoError(t, err)
 	assert.NotNil(t, secret)
-	assert.True(t, pkg.SecretsAreEqual(defaultSecret, secret))
+	assert.True(t, pkg.SecretsAreEqual(defaultSecret, secret, nil))
 }
 
 func Test_SecretsAreEqual_True(t *testing.T) {
-	equal := pkg.SecretsAreEqual(defaultSecret, testSecret)
+	equal := pkg.SecretsAreEqual(defaultSecret, testSecret, nil)
 	assert.True(t, equal)
 }
 
@@ -108,7 +111,7 @@ func Test_SecretsAreEqual_DifferentTypes(t *testing.T) {
 	secret := *testSecret
 	secret.Type = v1.SecretTypeOpaque
 
-	equal := pkg.SecretsAreEqual(defaultSecret, &secret)
+	equal := pkg.SecretsAreEqual(defaultSecret, &secret, nil)
 	assert.False(t, equal)
 }
 
@@ -116,7 +119,7 @@ func Test_SecretsAreEqual_DifferentData(t *testing.T) {
 	secret := *testSecret
 	secret.Data = map[string][]byte{"item": {65, 66}}
 
-	equal := pkg.SecretsAreEqual(defaultSecret, &secret)
+	equal := pkg.SecretsAreEqual(defaultSecret, &secret, nil)
 	assert.False(t, equal)
 }
 
@@ -124,7 +127,7 @@ func Test_SecretsAreEqual_DifferentStringData(t *testing.T) {
 	secret := *testSecret
 	secret.StringData = map[string]string{"something": "else"}
 
-	equal := pkg.SecretsAreEqual(defaultSecret, &secret)
+	equal := pkg.SecretsAreEqual(defaultSecret, &secret, nil)
 	assert.False(t, equal)
 }
 
@@ -132,7 +135,7 @@ func Test_SecretsAreEqual_DifferentAnnotations(t *testing.T) {
 	secret := *testSecret
 	secret.Annotations = map[string]string{"something": "else"}
 
-	equal := pkg.SecretsAreEqual(defaultSecret, &secret)
+	equal := pkg.SecretsAreEqual(defaultSecret, &secret, nil)
 	assert.False(t, equal)
 }
 
@@ -149,7 +152,7 @@ func Test_AnnotationsAreEqual_True(t *testing.T) {
 		"same-key": "same-value",
 	}
 
-	equal := pkg.AnnotationsAreEqual(a, b)
+	equal := pkg.AnnotationsAreEqual(a, b, nil)
 	assert.True(t, equal)
 }
 
@@ -162,7 +165,7 @@ func Test_AnnotationsAreEqual_False(t *testing.T) {
 		"same-key": "different-value",
 	}
 
-	equal := pkg.AnnotationsAreEqual(a, b)
+	equal := pkg.AnnotationsAreEqual(a, b, nil)
 	assert.False(t, equal)
 }
 
@@ -170,14 +173,16 @@ func Test_PrepareSecret(t *testing.T) {
 	secret := *defaultSecret
 	secret.Annotations = map[string]string{constants.LastAppliedConfigurationAnnotationKey: "some-previous-config", "some-key": "some-value"}
 
-	prepared := pkg.PrepareSecret(testSecretSyncRule, testNamespace, &secret)
+	prepared := PrepareSecret(testSecretSyncRule, testNamespace, &secret)
 
-	assert.True(t, pkg.SecretsAreEqual(&secret, prepared))
+	assert.True(t, pkg.SecretsAreEqual(&secret, prepared, nil))
 	assert.True(t, pkg.IsManagedBy(prepared))
 
 	lastConfig, ok := prepared.Annotations[constants.LastAppliedConfigurationAnnotationKey]
 	assert.False(t, ok)
 	assert.Equal(t, "", lastConfig)
+
+	assert.Equal(t, keyTestSecretSyncRule, prepared.Labels[constants.RuleLabelKey])
 }
 
 func Test_CopyAnnotations(t *testing.T) {
@@ -204,3 +209,213 @@ func Test_IsManagedBy_False(t *testing.T) {
 	isManaged := pkg.IsManagedBy(defaultSecret)
 	assert.False(t, isManaged)
 }
+
+func Test_SecretsAreEqual_DifferentLabels(t *testing.T) {
+	secret := *testSecret
+	secret.Labels = map[string]string{"something": "else"}
+
+	equal := pkg.SecretsAreEqual(defaultSecret, &secret, nil)
+	assert.False(t, equal)
+}
+
+func Test_LabelsAreEqual_Empty(t *testing.T) {
+	equal := pkg.LabelsAreEqual(nil, map[string]string{}, nil)
+	assert.True(t, equal)
+}
+
+func Test_PrepareSecret_TemplateKeepsSourceData(t *testing.T) {
+	rule := testSecretSyncRule.DeepCopy()
+	rule.Spec.Template = &typesv1.SecretTemplate{Data: map[string]string{"namespace": "{{ .Namespace.Name }}"}}
+
+	secret := defaultSecret.DeepCopy()
+	secret.Data = map[string][]byte{}
+
+	prepared := PrepareSecret(rule, testNamespace, secret)
+
+	assert.Equal(t, map[string][]byte{"namespace": []byte(keyTestNamespace)}, prepared.Data)
+	assert.Empty(t, secret.Data)
+}
+
+func Test_PrepareSecret_MetadataRules(t *testing.T) {
+	rule := testSecretSyncRule.DeepCopy()
+	rule.Spec.Rules.Labels = typesv1.MetadataRules{Exclude: types.StringSlice{"helm.sh/*"}, Extra: map[string]string{"team": "platform"}}
+	rule.Spec.Rules.Annotations = typesv1.MetadataRules{
+		Include: types.StringSlice{"example.com/*"},
+		Extra:   map[string]string{constants.ManagedByAnnotationKey: "someone-else"},
+	}
+
+	secret := *defaultSecret
+	secret.Labels = map[string]string{"helm.sh/chart": "app-1.0.0", "app": "web"}
+	secret.Annotations = map[string]string{"example.com/owner": "web", "example.com/team/lead": "jane", "meta.helm.sh/release-name": "app"}
+
+	prepared := PrepareSecret(rule, testNamespace, &secret)
+
+	assert.Equal(t, map[string]string{"app": "web", "team": "platform", constants.RuleLabelKey: keyTestSecretSyncRule, constants.RuleKindLabelKey: clientset.SecretSyncRule}, prepared.Labels)
+	assert.Equal(t, map[string]string{"example.com/owner": "web", "example.com/team/lead": "jane"}, pkg.CopyAnnotations(prepared.Annotations))
+	assert.Equal(t, constants.ManagedByAnnotationValue, prepared.Annotations[constants.ManagedByAnnotationKey])
+}
+
+func Test_SecretsAreEqual_IgnoresRecordedHash(t *testing.T) {
+	prepared := PrepareSecret(testSecretSyncRule, testNamespace, defaultSecret)
+
+	tampered := prepared.DeepCopy()
+	tampered.Data = map[string][]byte{"tampered": []byte("value")}
+
+	assert.False(t, pkg.SecretsAreEqual(prepared, tampered, nil))
+}
+
+func Test_SecretsAreEqual_MetadataRules(t *testing.T) {
+	rules := &typesv1.Rules{
+		Labels:      typesv1.MetadataRules{Exclude: types.StringSlice{"helm.sh/*"}},
+		Annotations: typesv1.MetadataRules{Include: types.StringSlice{"example.com/*"}, Extra: map[string]string{"extra": "value"}},
+	}
+
+	existing := PrepareSecret(testSecretSyncRule, testNamespace, defaultSecret)
+	existing.Labels["helm.sh/chart"] = "app-1.0.0"
+	existing.Annotations["argocd.argoproj.io/tracking-id"] = "app"
+	existing.Annotations["extra"] = "value"
+
+	prepared := PrepareSecret(testSecretSyncRule, testNamespace, defaultSecret)
+	prepared.Annotations["extra"] = "value"
+
+	assert.False(t, pkg.SecretsAreEqual(prepared, existing, nil))
+	assert.True(t, pkg.SecretsAreEqual(prepared, existing, rules))
+
+	existing.Annotations["extra"] = "changed"
+	assert.False(t, pkg.SecretsAreEqual(prepared, existing, rules))
+
+	existing.Annotations["extra"] = "value"
+	existing.Annotations["example.com/owner"] = "someone"
+	assert.False(t, pkg.SecretsAreEqual(prepared, existing, rules))
+}
+
+func Test_PrepareSecret_Provenance(t *testing.T) {
+	secret := *defaultSecret
+	secret.ResourceVersion = "42"
+
+	prepared := PrepareSecret(testSecretSyncRule, testNamespace, &secret)
+
+	assert.Equal(t, keyDefault, prepared.Annotations[constants.SourceNamespaceAnnotationKey])
+	assert.Equal(t, keyDefaultSecret, prepared.Annotations[constants.SourceNameAnnotationKey])
+	assert.Equal(t, "42", prepared.Annotations[constants.SourceResourceVersionAnnotationKey])
+	assert.Equal(t, keyTestSecretSyncRule, prepared.Annotations[constants.RuleAnnotationKey])
+	assert.Equal(t, pkg.ContentHash(prepared, &testSecretSyncRule.Spec.Rules), prepared.Annotations[constants.ContentHashAnnotationKey])
+
+	rule := testSecretSyncRule.DeepCopy()
+	rule.Namespace = keyDefault
+
+	prepared = PrepareSecret(rule, testNamespace, &secret)
+	assert.Equal(t, keyDefault+"/"+keyTestSecretSyncRule, prepared.Annotations[constants.RuleAnnotationKey])
+}
+
+func Test_ContentHash_IgnoresProvenance(t *testing.T) {
+	secret := *defaultSecret
+	secret.ResourceVersion = "1"
+	first := PrepareSecret(testSecretSyncRule, testNamespace, &secret)
+
+	secret.ResourceVersion = "2"
+	second := PrepareSecret(testSecretSyncRule, testNamespace, &secret)
+
+	assert.Equal(t, first.Annotations[constants.ContentHashAnnotationKey], second.Annotations[constants.ContentHashAnnotationKey])
+	assert.Equal(t, pkg.ContentHash(defaultSecret, nil), pkg.ContentHash(first, nil))
+
+	secret.Data = map[string][]byte{"changed": []byte("value")}
+	changed := PrepareSecret(testSecretSyncRule, testNamespace, &secret)
+
+	assert.NotEqual(t, first.Annotations[constants.ContentHashAnnotationKey], changed.Annotations[constants.ContentHashAnnotationKey])
+}
+
+func Test_SyncSecret_SkipsUnchangedContent(t *testing.T) {
+	source := defaultSecret.DeepCopy()
+	source.ResourceVersion = "1"
+	synced := PrepareSecret(testSecretSyncRule, testNamespace, source)
+	source.ResourceVersion = "2"
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, source, synced, testSecretSyncRule)
+
+	action, err := client.SyncSecret(testSecretSyncRule, testNamespace, source)
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.SyncActionNone, action)
+
+	secret, err := client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", secret.Annotations[constants.SourceResourceVersionAnnotationKey])
+}
+
+func Test_SyncSecret_RecordsProvenance(t *testing.T) {
+	synced := PrepareSecret(testSecretSyncRule, testNamespace, defaultSecret)
+	for _, key := range []string{constants.SourceNamespaceAnnotationKey, constants.SourceNameAnnotationKey, constants.RuleAnnotationKey, constants.ContentHashAnnotationKey} {
+		delete(synced.Annotations, key)
+	}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, synced, testSecretSyncRule)
+
+	action, err := client.SyncSecret(testSecretSyncRule, testNamespace, defaultSecret)
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.SyncActionUpdated, action)
+
+	secret, err := client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.Equal(t, keyTestSecretSyncRule, secret.Annotations[constants.RuleAnnotationKey])
+	assert.NotEmpty(t, secret.Annotations[constants.ContentHashAnnotationKey])
+}
+
+func Test_EnqueueDriftedSecret_Modified(t *testing.T) {
+	drifted := PrepareSecret(testSecretSyncRule, testNamespace, defaultSecret)
+	drifted.Data = map[string][]byte{"drifted": []byte("value")}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, testSecretSyncRule)
+
+	client.EnqueueDriftedSecret(drifted, false)
+	assert.Equal(t, 1, client.Queue.Len())
+}
+
+func Test_EnqueueDriftedSecret_Unchanged(t *testing.T) {
+	copy := PrepareSecret(testSecretSyncRule, testNamespace, defaultSecret)
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, testSecretSyncRule)
+
+	client.EnqueueDriftedSecret(copy, false)
+	assert.Equal(t, 0, client.Queue.Len())
+}
+
+func Test_EnqueueDriftedSecret_Deleted(t *testing.T) {
+	copy := PrepareSecret(testSecretSyncRule, testNamespace, defaultSecret)
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, testSecretSyncRule)
+
+	client.EnqueueDriftedSecret(copy, true)
+	assert.Equal(t, 1, client.Queue.Len())
+}
+
+func Test_EnqueueDriftedSecret_DeletedNoRule(t *testing.T) {
+	copy := PrepareSecret(testSecretSyncRule, testNamespace, defaultSecret)
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret)
+
+	client.EnqueueDriftedSecret(copy, true)
+	assert.Equal(t, 0, client.Queue.Len())
+}
+
+func Test_TransformData(t *testing.T) {
+	keys := &typesv1.KeyRules{
+		Exclude: types.StringSlice{"*.key"},
+		Rename:  map[string]string{"password": "DB_PASSWORD", "DB_USER": "user"},
+	}
+	data := map[string][]byte{
+		"tls.key":     []byte("key"),
+		"password":    []byte("password"),
+		"DB_PASSWORD": []byte("replaced"),
+		"DB_USER":     []byte("user"),
+	}
+
+	transformed := pkg.TransformData(keys, data)
+	assert.Equal(t, map[string][]byte{"DB_PASSWORD": []byte("password"), "user": []byte("user")}, transformed)
+}
+
+func Test_TransformData_AllExcluded(t *testing.T) {
+	keys := &typesv1.KeyRules{Include: types.StringSlice{"ca.crt"}}
+
+	transformed := pkg.TransformData(keys, map[string][]byte{"tls.key": []byte("key")})
+	assert.Nil(t, transformed)
+}
@@ -1,87 +1,258 @@
 package client
 
 import (
+	"fmt"
+
 	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/constants"
 	log "github.com/sirupsen/logrus"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/watch"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/cache"
 )
 
-func (client *Client) SecretSyncRuleEventHandler(event watch.Event) error {
-	rule, ok := event.Object.(*typesv1.SecretSyncRule)
+func (client *Client) SecretSyncRuleEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    client.AddedSecretSyncRuleHandler,
+		UpdateFunc: client.ModifiedSecretSyncRuleHandler,
+		DeleteFunc: client.DeletedSecretSyncRuleHandler,
+	}
+}
+
+func (client *Client) AddedSecretSyncRuleHandler(obj interface{}) {
+	rule, ok := obj.(*typesv1.SecretSyncRule)
+	if !ok {
+		log.Error("failed to cast SecretSyncRule")
+		return
+	}
+
+	ruleLogger(rule).Infof("added")
+	client.Enqueue(rule)
+}
+
+// ModifiedSecretSyncRuleHandler handles syncing secrets after a SecretSyncRule has been modified
+//
+// Rather than diffing the previous and new state of the rule, a modified SecretSyncRule is resynced across all applicable namespaces.
+// Every Secret synced by the rule carries the rule label, so copies left in namespaces that the rule no longer targets
+// are found through the Secret informer index and removed during the same sync.
+func (client *Client) ModifiedSecretSyncRuleHandler(oldObj, newObj interface{}) {
+	rule, ok := newObj.(*typesv1.SecretSyncRule)
 	if !ok {
 		log.Error("failed to cast SecretSyncRule")
-		return nil
+		return
 	}
 
-	switch event.Type {
-	case watch.Added:
-		return client.AddedSecretSyncRuleHandler(rule)
-	case watch.Modified:
-		return client.ModifiedSecretSyncRuleHandler(rule)
-	case watch.Deleted:
-		return client.DeletedSecretSyncRuleHandler(rule)
+	if old, ok := oldObj.(*typesv1.SecretSyncRule); ok {
+		if old.ResourceVersion == rule.ResourceVersion {
+			ruleLogger(rule).Debugf("periodic resync")
+			client.Enqueue(rule)
+			return
+		}
+
+		if old.Generation == rule.Generation && old.DeletionTimestamp.Equal(rule.DeletionTimestamp) {
+			ruleLogger(rule).Debugf("status or metadata updated")
+			return
+		}
 	}
 
-	return nil
+	ruleLogger(rule).Infof("modified")
+	client.Enqueue(rule)
 }
 
-func (client *Client) AddedSecretSyncRuleHandler(rule *typesv1.SecretSyncRule) error {
-	ruleLogger(rule).Infof("added")
+func (client *Client) DeletedSecretSyncRuleHandler(obj interface{}) {
+	rule, ok := objectFromTombstone(obj).(*typesv1.SecretSyncRule)
+	if !ok {
+		log.Error("failed to cast SecretSyncRule")
+		return
+	}
+
+	ruleLogger(rule).Infof("deleted")
+	client.Enqueue(rule)
+}
 
-	secret, err := client.GetSecret(rule.Spec.Secret.Namespace, rule.Spec.Secret.Name)
+// SyncSecretSyncRule reconciles the SecretSyncRule with the given key against the informer caches.
+// It computes the desired set of synced Secrets and creates, updates or deletes copies that drifted from it,
+// so it is safe to call repeatedly for the same key.
+func (client *Client) SyncSecretSyncRule(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
 		return err
 	}
-
-	for _, namespace := range rule.Namespaces(client.Context, client.DefaultClientset) {
-		client.CreateUpdateSecret(rule, &namespace, secret)
+	if namespace != "" {
+		return client.SyncNamespacedSecretSyncRule(namespace, name)
 	}
 
-	return nil
-}
+	rule, err := client.SecretSyncRuleLister.Get(key)
+	if errors.IsNotFound(err) {
+		logger := ruleNameLogger(key)
+		logger.Debugf("no longer exists, removing synced secrets")
+
+		summary := new(SyncSummary)
+		err := client.PruneOwnedSecrets(key, typesv1.Rules{}, nil, RuleDeletionPolicyOf, summary)
+		summary.Log(logger)
+		return err
+	}
+	if err != nil {
+		return err
+	}
 
-// modifiedSecretSyncRuleHandler handles syncing secrets after a SecretSyncRule has been modified
-//
-// Due to the event watcher only providing the new state of the modified resource, it is impossible to know the previous state.
-// (The exception to this is potentially "applied" changes and parsing the last-applied-configuration annotation)
-// In coping with this limitation, a modified SecretSyncRule will simply attempt to resync the rule across all applicable namespaces.
-func (client *Client) ModifiedSecretSyncRuleHandler(rule *typesv1.SecretSyncRule) error {
 	if rule.DeletionTimestamp != nil {
-		return nil
+		return client.FinalizeSecretSyncRule(rule)
 	}
 
-	ruleLogger(rule).Infof("modified")
+	if !HasFinalizer(rule) {
+		if rule, err = client.AddSecretSyncRuleFinalizer(rule); err != nil {
+			return err
+		}
+	}
 
-	secret, err := client.GetSecret(rule.Spec.Secret.Namespace, rule.Spec.Secret.Name)
+	logger := ruleLogger(rule)
+	logger.Debugf("syncing")
+
+	summary := new(SyncSummary)
+	err = client.syncSecretSyncRule(rule, summary)
+	summary.Log(logger)
+
+	if IsRuleError(err) {
+		err = nil
+	}
+
+	return utilerrors.NewAggregate([]error{err, client.UpdateSecretSyncRuleStatus(rule, summary)})
+}
+
+func (client *Client) syncSecretSyncRule(rule *typesv1.SecretSyncRule, summary *SyncSummary) error {
+	logger := ruleLogger(rule)
+
+	secrets, missing, err := client.SourceSecrets(rule)
 	if err != nil {
+		if IsRuleError(err) {
+			logger.Errorf("invalid source: %s", err.Error())
+			summary.InvalidSource = err.Error()
+		}
 		return err
 	}
 
-	for _, namespace := range rule.Namespaces(client.Context, client.DefaultClientset) {
-		client.CreateUpdateSecret(rule, &namespace, secret)
+	summary.Sources = len(secrets)
+	for _, reference := range missing {
+		logger.Debugf("source %s does not exist", reference)
+		summary.MissingSources = append(summary.MissingSources, reference)
+	}
+
+	if rule.Spec.Merge != nil {
+		if len(missing) > 0 {
+			logger.Infof("not merging source secrets while some of them do not exist")
+			return nil
+		}
+		if len(secrets) > 0 {
+			secrets = []*v1.Secret{MergedSecret(rule, secrets)}
+		}
+	}
+
+	namespaces := rule.Namespaces(client.NamespaceLister)
+	summary.Targets = len(namespaces)
+	summary.Refused(rule.RefusedNamespaces(client.NamespaceLister))
+
+	desired, errs := syncSecretsToNamespaces(rule, namespaces, secrets, summary, client.SyncSecret)
+
+	if err := client.PruneOwnedSecrets(ruleKey(rule), rule.Spec.Rules, desired, client.SourceDeletionPolicy(rule), summary); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, cluster := range rule.Spec.Clusters {
+		if err := client.syncRemoteCluster(rule, cluster, secrets, summary.Cluster(cluster)); err != nil && !IsRuleError(err) {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, cluster := range RemovedClusters(rule) {
+		if err := client.pruneRemovedCluster(rule, cluster, summary); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
-	return nil
+	return utilerrors.NewAggregate(errs)
 }
 
-func (client *Client) DeletedSecretSyncRuleHandler(rule *typesv1.SecretSyncRule) error {
-	ruleLogger(rule).Infof("deleted")
+// syncSecretsToNamespaces syncs every given Secret to every given Namespace with the given sync function,
+// recording the outcome in the given summary. It returns the synced Secrets that are desired and the errors worth retrying.
+func syncSecretsToNamespaces(rule *typesv1.SecretSyncRule, namespaces []*v1.Namespace, secrets []*v1.Secret, summary *SyncSummary,
+	sync func(rule *typesv1.SecretSyncRule, namespace *v1.Namespace, secret *v1.Secret) (SyncAction, error)) (map[types.NamespacedName]bool, []error) {
+	var errs []error
+	desired := make(map[types.NamespacedName]bool)
+
+	for _, namespace := range namespaces {
+		for _, secret := range secrets {
+			name, err := rule.TargetName(secret, namespace)
+			if err != nil {
+				summary.Failed(namespace.Name, ReasonInvalidTargetName, err)
+				continue
+			}
+
+			target := types.NamespacedName{Namespace: namespace.Name, Name: name}
+			if desired[target] {
+				summary.Failed(namespace.Name, ReasonTargetNameConflict, fmt.Errorf("%s/%s: %w", secret.Namespace, secret.Name, constants.ErrTargetNameConflict))
+				continue
+			}
+			desired[target] = true
+
+			action, err := sync(rule, namespace, secret)
+			switch {
+			case IsRuleError(err):
+				summary.Failed(namespace.Name, "", err)
+			case err != nil:
+				errs = append(errs, err)
+				summary.Failed(namespace.Name, "", err)
+			case action == SyncActionConflict:
+				summary.Conflicted(namespace.Name, name, action)
+				summary.Failed(namespace.Name, ReasonSecretNotManaged, fmt.Errorf("%s: %w", name, constants.ErrSecretNotManaged))
+			case action == SyncActionSkipped:
+				summary.Conflicted(namespace.Name, name, action)
+			default:
+				if _, ok := ConflictResolutions[action]; ok {
+					summary.Conflicted(namespace.Name, name, action)
+				}
+				summary.Succeeded(namespace.Name, action)
+			}
+		}
+	}
+
+	return desired, errs
+}
 
-	secret, err := client.GetSecret(rule.Spec.Secret.Namespace, rule.Spec.Secret.Name)
+// GetSecretSyncRule returns the cached SecretSyncRule with the given key,
+// converting NamespacedSecretSyncRules that are referenced by their namespace and name.
+func (client *Client) GetSecretSyncRule(key string) (*typesv1.SecretSyncRule, error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if namespace == "" {
+		return client.SecretSyncRuleLister.Get(name)
+	}
+
+	rule, err := client.NamespacedSecretSyncRuleLister.Get(namespace, name)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, namespace := range rule.Namespaces(client.Context, client.DefaultClientset) {
-		client.SyncDeletedSecret(rule.Spec.Rules, &namespace, secret)
+	return client.ConvertNamespacedSecretSyncRule(rule)
+}
+
+// ruleKey returns the key of the given SecretSyncRule in the work queue and the rule index.
+func ruleKey(rule *typesv1.SecretSyncRule) string {
+	if rule.Namespace != "" {
+		return rule.Namespace + "/" + rule.Name
 	}
 
-	return nil
+	return rule.Name
 }
 
-func (client *Client) ListSecretSyncRules() (rules *typesv1.SecretSyncRuleList, err error) {
-	rules, err = client.KubeSecretSyncClientset.SecretSyncRules().List(client.Context, metav1.ListOptions{})
+func (client *Client) ListSecretSyncRules() (rules []*typesv1.SecretSyncRule, err error) {
+	rules, err = client.SecretSyncRuleLister.List(labels.Everything())
 	if err != nil {
 		log.Errorf("failed to list SecretSyncRules: %s", err.Error())
 	}
@@ -0,0 +1,401 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/alehechka/kube-secret-sync/api/types"
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	pkg "github.com/alehechka/kube-secret-sync/client"
+	"github.com/alehechka/kube-secret-sync/constants"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_SyncSecretSyncRule(t *testing.T) {
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, testSecretSyncRule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err := client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.NotNil(t, secret)
+}
+
+func Test_SyncSecretSyncRule_NoSecret(t *testing.T) {
+	client := InitializeTestClientset(defaultNamespace, testNamespace, testSecretSyncRule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	_, err = client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func Test_SyncSecretSyncRule_NoRule(t *testing.T) {
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	_, err = client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func Test_ListSecretSyncRules(t *testing.T) {
+	client := InitializeTestClientset(testSecretSyncRule)
+
+	rules, err := client.ListSecretSyncRules()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(rules))
+}
+
+func Test_SyncSecretSyncRule_RepairsDrift(t *testing.T) {
+	excluded := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: keyExcludedNamespace}}
+
+	rule := testSecretSyncRule.DeepCopy()
+	rule.Spec.Rules.Namespaces.Exclude = types.StringSlice{keyExcludedNamespace}
+
+	drifted := PrepareSecret(rule, testNamespace, defaultSecret)
+	drifted.Data = map[string][]byte{"drifted": []byte("value")}
+
+	stale := PrepareSecret(rule, excluded, defaultSecret)
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, excluded, defaultSecret, drifted, stale, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err := client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.True(t, pkg.SecretsAreEqual(defaultSecret, secret, nil))
+
+	_, err = client.GetSecret(keyExcludedNamespace, keyDefaultSecret)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func Test_SyncSecretSyncRule_PrunesUntargeted(t *testing.T) {
+	excluded := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: keyExcludedNamespace}}
+
+	rule := testSecretSyncRule.DeepCopy()
+	rule.Spec.Rules.Namespaces.Include = types.StringSlice{keyTestNamespace}
+
+	unmanaged := PrepareSecret(rule, excluded, defaultSecret)
+	unmanaged.Annotations = nil
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, excluded, defaultSecret, unmanaged, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	_, err = client.GetSecret(keyExcludedNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+}
+
+func Test_SyncSecretSyncRule_PrunesUntargetedForce(t *testing.T) {
+	excluded := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: keyExcludedNamespace}}
+
+	rule := testSecretSyncRule.DeepCopy()
+	rule.Spec.Rules.Force = true
+	rule.Spec.Rules.Namespaces.Include = types.StringSlice{keyTestNamespace}
+
+	unmanaged := PrepareSecret(rule, excluded, defaultSecret)
+	unmanaged.Annotations = nil
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, excluded, defaultSecret, unmanaged, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	_, err = client.GetSecret(keyExcludedNamespace, keyDefaultSecret)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func Test_SyncSecretSyncRule_SecretSelector(t *testing.T) {
+	selected := map[string]string{"sync": "true"}
+
+	first := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: keyDefault, Labels: selected}}
+	second := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "second", Namespace: keyDefault, Labels: selected}}
+	ignored := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "ignored", Namespace: keyDefault}}
+
+	rule := &typesv1.SecretSyncRule{
+		ObjectMeta: metav1.ObjectMeta{Name: keyTestSecretSyncRule},
+		Spec: typesv1.SecretSyncRuleSpec{SecretSelector: &typesv1.SecretSelector{
+			Namespace:     keyDefault,
+			LabelSelector: metav1.LabelSelector{MatchLabels: selected},
+		}},
+	}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, first, second, ignored, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secrets, err := client.ListSecrets(keyTestNamespace)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(secrets.Items))
+}
+
+func Test_EnqueueSecret_StoppedMatching(t *testing.T) {
+	selected := map[string]string{"sync": "true"}
+
+	rule := &typesv1.SecretSyncRule{
+		ObjectMeta: metav1.ObjectMeta{Name: keyTestSecretSyncRule},
+		Spec: typesv1.SecretSyncRuleSpec{SecretSelector: &typesv1.SecretSelector{
+			Namespace:     keyDefault,
+			LabelSelector: metav1.LabelSelector{MatchLabels: selected},
+		}},
+	}
+
+	client := InitializeTestClientset(rule)
+
+	old := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: keyDefault, Labels: selected}}
+	relabelled := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: keyDefault}}
+
+	client.EnqueueSecret(relabelled, "modified", old)
+	assert.Equal(t, 1, client.Queue.Len())
+}
+
+func Test_SyncSecretSyncRule_TargetName(t *testing.T) {
+	rule := testSecretSyncRule.DeepCopy()
+	rule.Spec.Target = &typesv1.Target{Name: "{{ .Source.Namespace }}-{{ .Source.Name }}"}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err := client.GetSecret(keyTestNamespace, keyDefault+"-"+keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.True(t, pkg.SecretsAreEqual(defaultSecret, secret, nil))
+
+	_, err = client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func Test_SyncSecretSyncRule_TargetNameConflict(t *testing.T) {
+	other := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+	otherSecret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: keyDefaultSecret, Namespace: "other"}}
+
+	rule := testSecretSyncRule.DeepCopy()
+	rule.Spec.Secrets = []typesv1.Secret{{Name: keyDefaultSecret, Namespace: "other"}}
+	rule.Spec.Rules.Namespaces.Include = types.StringSlice{keyTestNamespace}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, other, defaultSecret, otherSecret, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	updated, err := client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(updated.Status.Failures))
+	assert.Equal(t, pkg.ReasonTargetNameConflict, updated.Status.Failures[0].Reason)
+}
+
+func Test_SyncSecretSyncRule_KeyRules(t *testing.T) {
+	source := defaultSecret.DeepCopy()
+	source.Data = map[string][]byte{"ca.crt": []byte("ca"), "tls.key": []byte("key")}
+
+	rule := testSecretSyncRule.DeepCopy()
+	rule.Spec.Rules.Keys = typesv1.KeyRules{Include: types.StringSlice{"ca.crt"}, Rename: map[string]string{"ca.crt": "ca.pem"}}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, source, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err := client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"ca.pem": []byte("ca")}, secret.Data)
+
+	assert.NoError(t, client.SecretInformer.GetIndexer().Add(secret))
+
+	action, err := client.SyncSecret(rule, testNamespace, source)
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.SyncActionNone, action)
+}
+
+func Test_SyncSecretSyncRule_Template(t *testing.T) {
+	source := defaultSecret.DeepCopy()
+	source.Data = map[string][]byte{"user": []byte("app")}
+
+	rule := testSecretSyncRule.DeepCopy()
+	rule.Spec.Template = &typesv1.SecretTemplate{Data: map[string]string{"url": "postgres://{{ .Data.user }}@db.{{ .Namespace.Name }}.svc"}}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, source, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err := client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.Equal(t, "postgres://app@db."+keyTestNamespace+".svc", string(secret.Data["url"]))
+	assert.Equal(t, "app", string(secret.Data["user"]))
+}
+
+func Test_SyncSecretSyncRule_TemplateError(t *testing.T) {
+	rule := testSecretSyncRule.DeepCopy()
+	rule.Spec.Template = &typesv1.SecretTemplate{Data: map[string]string{"url": "postgres://{{ .Data.missing }}@db"}}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	_, err = client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.True(t, errors.IsNotFound(err))
+
+	updated, err := client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(updated.Status.Failures))
+	assert.Equal(t, pkg.ReasonInvalidTemplate, updated.Status.Failures[0].Reason)
+}
+
+func Test_MergedSecret(t *testing.T) {
+	rule := testSecretSyncRule.DeepCopy()
+	rule.Spec.Merge = &typesv1.Merge{Name: "app-secrets", Sources: []typesv1.MergeSource{
+		{Secret: typesv1.Secret{Name: "api", Namespace: "payments"}, Prefix: "API_"},
+	}}
+
+	db := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "database"},
+		Data:       map[string][]byte{"password": []byte("db"), "API_TOKEN": []byte("db")},
+	}
+	api := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "payments"},
+		Data:       map[string][]byte{"TOKEN": []byte("api")},
+	}
+
+	merged := pkg.MergedSecret(rule, []*v1.Secret{db, api})
+	assert.Equal(t, "app-secrets", merged.Name)
+	assert.Equal(t, v1.SecretTypeOpaque, merged.Type)
+	assert.Equal(t, map[string][]byte{"password": []byte("db"), "API_TOKEN": []byte("api")}, merged.Data)
+}
+
+func Test_SyncSecretSyncRule_Merge(t *testing.T) {
+	other := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+	otherSecret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "other"}, Data: map[string][]byte{"key": []byte("other")}}
+
+	source := defaultSecret.DeepCopy()
+	source.Data = map[string][]byte{"key": []byte("default")}
+
+	rule := testSecretSyncRule.DeepCopy()
+	rule.Spec.Merge = &typesv1.Merge{Name: "merged", Sources: []typesv1.MergeSource{
+		{Secret: typesv1.Secret{Name: "other", Namespace: "other"}, Prefix: "OTHER_"},
+	}}
+	rule.Spec.Rules.Namespaces.Include = types.StringSlice{keyTestNamespace}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, other, source, otherSecret, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err := client.GetSecret(keyTestNamespace, "merged")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"key": []byte("default"), "OTHER_key": []byte("other")}, secret.Data)
+
+	secrets, err := client.ListSecrets(keyTestNamespace)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(secrets.Items))
+}
+
+func Test_SyncSecretSyncRule_MergeMissingSource(t *testing.T) {
+	rule := testSecretSyncRule.DeepCopy()
+	rule.Spec.Merge = &typesv1.Merge{Name: "merged", Sources: []typesv1.MergeSource{
+		{Secret: typesv1.Secret{Name: "missing", Namespace: keyDefault}},
+	}}
+
+	existing := PrepareSecret(rule, testNamespace, pkg.MergedSecret(rule, []*v1.Secret{defaultSecret}))
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, existing, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	_, err = client.GetSecret(keyTestNamespace, "merged")
+	assert.NoError(t, err)
+}
+
+func Test_SyncSecretSyncRule_RuleDeletionPolicy(t *testing.T) {
+	orphan := testSecretSyncRule.DeepCopy()
+	orphan.Spec.DeletionPolicy = &typesv1.DeletionPolicy{OnRuleDeletion: typesv1.DeletionPolicyOrphan}
+
+	orphaned := PrepareSecret(orphan, testNamespace, defaultSecret)
+	assert.Empty(t, orphaned.OwnerReferences)
+	assert.Equal(t, string(typesv1.DeletionPolicyOrphan), orphaned.Annotations[constants.DeletionPolicyAnnotationKey])
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, orphaned)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err := client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.False(t, pkg.IsManagedBy(secret))
+	assert.NotContains(t, secret.Labels, constants.RuleLabelKey)
+	assert.NotContains(t, secret.Annotations, constants.DeletionPolicyAnnotationKey)
+	for _, key := range []string{constants.SourceNamespaceAnnotationKey, constants.SourceNameAnnotationKey, constants.SourceResourceVersionAnnotationKey, constants.RuleAnnotationKey, constants.ContentHashAnnotationKey} {
+		assert.NotContains(t, secret.Annotations, key)
+	}
+
+	retain := testSecretSyncRule.DeepCopy()
+	retain.Spec.DeletionPolicy = &typesv1.DeletionPolicy{OnRuleDeletion: typesv1.DeletionPolicyRetain}
+
+	client = InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, PrepareSecret(retain, testNamespace, defaultSecret))
+
+	err = client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err = client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.True(t, pkg.IsManagedBy(secret))
+}
+
+func Test_SyncSecretSyncRule_AdoptsCopiesOfDeletedRules(t *testing.T) {
+	retain := testSecretSyncRule.DeepCopy()
+	retain.Name = "deleted-secret-sync-rule"
+	retain.Spec.DeletionPolicy = &typesv1.DeletionPolicy{OnRuleDeletion: typesv1.DeletionPolicyRetain}
+
+	retained := PrepareSecret(retain, testNamespace, defaultSecret)
+	retained.Data = map[string][]byte{"retained": []byte("by the deleted rule")}
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, retained, testSecretSyncRule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err := client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultSecret.Data, secret.Data)
+	assert.Equal(t, keyTestSecretSyncRule, secret.Labels[constants.RuleLabelKey])
+
+	rule, err := client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, rule.Status.Conflicts)
+}
+
+func Test_SyncSecretSyncRule_SourceDeletionPolicy(t *testing.T) {
+	excluded := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: keyExcludedNamespace}}
+
+	rule := testSecretSyncRule.DeepCopy()
+	rule.Spec.Rules.Namespaces.Exclude = types.StringSlice{keyExcludedNamespace}
+	rule.Spec.DeletionPolicy = &typesv1.DeletionPolicy{OnSourceDeletion: typesv1.DeletionPolicyOrphan}
+
+	synced := PrepareSecret(rule, testNamespace, defaultSecret)
+	stale := PrepareSecret(rule, excluded, defaultSecret)
+
+	client := InitializeTestClientset(defaultNamespace, testNamespace, excluded, synced, stale, rule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	secret, err := client.GetSecret(keyTestNamespace, keyDefaultSecret)
+	assert.NoError(t, err)
+	assert.False(t, pkg.IsManagedBy(secret))
+	assert.NotContains(t, secret.Labels, constants.RuleLabelKey)
+
+	_, err = client.GetSecret(keyExcludedNamespace, keyDefaultSecret)
+	assert.True(t, errors.IsNotFound(err))
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"errors"
+	"sort"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/constants"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SourceSecrets returns the cached source Secrets and the external sources of the given SecretSyncRule in merge order,
+// along with the explicitly referenced Secrets and external sources that do not exist.
+func (client *Client) SourceSecrets(rule *typesv1.SecretSyncRule) (secrets []*v1.Secret, missing []string, err error) {
+	seen := make(map[typesv1.Secret]bool)
+
+	for _, reference := range rule.Spec.SecretReferences() {
+		if seen[reference] {
+			continue
+		}
+		seen[reference] = true
+
+		secret, err := client.SecretLister.Secrets(reference.Namespace).Get(reference.Name)
+		if apierrors.IsNotFound(err) {
+			missing = append(missing, reference.Namespace+"/"+reference.Name)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		secrets = append(secrets, secret)
+	}
+
+	if selector := rule.Spec.SecretSelector; selector != nil {
+		selected, err := client.SecretLister.Secrets(selector.Namespace).List(selector.Selector())
+		if err != nil {
+			return nil, nil, err
+		}
+		sort.Slice(selected, func(i, j int) bool { return selected[i].Name < selected[j].Name })
+
+		for _, secret := range selected {
+			reference := typesv1.Secret{Name: secret.Name, Namespace: secret.Namespace}
+			if !seen[reference] && !IsManagedBy(secret) {
+				seen[reference] = true
+				secrets = append(secrets, secret)
+			}
+		}
+	}
+
+	for _, source := range rule.Spec.External {
+		secret, err := client.ExternalSecret(source)
+		if errors.Is(err, constants.ErrSourceNotFound) {
+			missing = append(missing, source.Identifier())
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		secrets = append(secrets, secret)
+	}
+
+	return
+}
+
+// SourceSecretFor returns the cached source Secret of the given SecretSyncRule that the given synced Secret was copied from.
+func (client *Client) SourceSecretFor(rule *typesv1.SecretSyncRule, secret *v1.Secret) (*v1.Secret, bool) {
+	sources, _, err := client.SourceSecrets(rule)
+	if err != nil {
+		return nil, false
+	}
+
+	namespace, err := client.NamespaceLister.Get(secret.Namespace)
+	if err != nil {
+		namespace = &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: secret.Namespace}}
+	}
+
+	if rule.Spec.Merge != nil {
+		if len(sources) == 0 {
+			return nil, false
+		}
+		sources = []*v1.Secret{MergedSecret(rule, sources)}
+	}
+
+	for _, source := range sources {
+		if name, err := rule.TargetName(source, namespace); err == nil && name == secret.Name {
+			return source, true
+		}
+	}
+
+	return nil, false
+}
+
+// MergedSecret combines the given source Secrets of a merging SecretSyncRule into a single Secret named after the merge.
+// Keys of later sources replace keys of earlier ones. The merged Secret keeps the type of its sources
+// if they all share it and no keys are prefixed, otherwise it is Opaque.
+func MergedSecret(rule *typesv1.SecretSyncRule, sources []*v1.Secret) *v1.Secret {
+	merged := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: rule.Spec.Merge.Name},
+		Data:       make(map[string][]byte),
+	}
+
+	for i, source := range sources {
+		prefix := rule.Spec.Merge.KeyPrefix(source)
+
+		if i == 0 {
+			merged.Type = source.Type
+		}
+		if merged.Type != source.Type || prefix != "" {
+			merged.Type = v1.SecretTypeOpaque
+		}
+
+		for key, value := range source.Data {
+			if _, ok := merged.Data[prefix+key]; ok {
+				ruleLogger(rule).Debugf("key %s of source secret %s/%s replaces an earlier source", prefix+key, source.Namespace, source.Name)
+			}
+			merged.Data[prefix+key] = value
+		}
+	}
+
+	if merged.Type == "" {
+		merged.Type = v1.SecretTypeOpaque
+	}
+
+	return merged
+}
@@ -0,0 +1,248 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// UpdateSecretSyncRuleStatus writes the outcome of a sync to the status subresource of the given SecretSyncRule.
+// The status is only written when it changed or when synced Secrets were changed.
+func (client *Client) UpdateSecretSyncRuleStatus(rule *typesv1.SecretSyncRule, summary *SyncSummary) error {
+	status := SecretSyncRuleStatus(rule, summary)
+
+	if !summary.Changed() && StatusesAreEqual(&rule.Status, status) {
+		return nil
+	}
+
+	now := metav1.Now()
+	status.LastSyncTime = &now
+
+	updated := rule.DeepCopy()
+	updated.Status = *status
+
+	_, err := client.KubeSecretSyncClientset.SecretSyncRules().UpdateStatus(client.Context, updated, metav1.UpdateOptions{})
+	if err != nil {
+		ruleLogger(rule).Errorf("failed to update status: %s", err.Error())
+	}
+
+	return err
+}
+
+// UpdateNamespacedSecretSyncRuleStatus writes the outcome of a sync to the status subresource of the given NamespacedSecretSyncRule.
+// The status is only written when it changed or when synced Secrets were changed.
+func (client *Client) UpdateNamespacedSecretSyncRuleStatus(rule *typesv1.NamespacedSecretSyncRule, summary *SyncSummary) error {
+	status := SyncRuleStatus(&rule.Status, rule.Generation, summary)
+
+	if !summary.Changed() && StatusesAreEqual(&rule.Status, status) {
+		return nil
+	}
+
+	now := metav1.Now()
+	status.LastSyncTime = &now
+
+	updated := rule.DeepCopy()
+	updated.Status = *status
+
+	_, err := client.KubeSecretSyncClientset.NamespacedSecretSyncRules(rule.Namespace).UpdateStatus(client.Context, updated, metav1.UpdateOptions{})
+	if err != nil {
+		namespacedRuleLogger(rule).Errorf("failed to update status: %s", err.Error())
+	}
+
+	return err
+}
+
+// UpdateConfigMapSyncRuleStatus writes the outcome of a sync to the status subresource of the given ConfigMapSyncRule.
+// The status is only written when it changed or when synced ConfigMaps were changed.
+func (client *Client) UpdateConfigMapSyncRuleStatus(rule *typesv1.ConfigMapSyncRule, summary *SyncSummary) error {
+	status := SyncRuleStatus(&rule.Status, rule.Generation, summary)
+
+	if !summary.Changed() && StatusesAreEqual(&rule.Status, status) {
+		return nil
+	}
+
+	now := metav1.Now()
+	status.LastSyncTime = &now
+
+	updated := rule.DeepCopy()
+	updated.Status = *status
+
+	_, err := client.KubeSecretSyncClientset.ConfigMapSyncRules().UpdateStatus(client.Context, updated, metav1.UpdateOptions{})
+	if err != nil {
+		configMapRuleLogger(rule).Errorf("failed to update status: %s", err.Error())
+	}
+
+	return err
+}
+
+// UpdateResourceSyncRuleStatus writes the outcome of a sync to the status subresource of the given ResourceSyncRule.
+// The status is only written when it changed or when synced resources were changed.
+func (client *Client) UpdateResourceSyncRuleStatus(rule *typesv1.ResourceSyncRule, summary *SyncSummary) error {
+	status := SyncRuleStatus(&rule.Status, rule.Generation, summary)
+
+	if !summary.Changed() && StatusesAreEqual(&rule.Status, status) {
+		return nil
+	}
+
+	now := metav1.Now()
+	status.LastSyncTime = &now
+
+	updated := rule.DeepCopy()
+	updated.Status = *status
+
+	_, err := client.KubeSecretSyncClientset.ResourceSyncRules().UpdateStatus(client.Context, updated, metav1.UpdateOptions{})
+	if err != nil {
+		resourceRuleLogger(rule).Errorf("failed to update status: %s", err.Error())
+	}
+
+	return err
+}
+
+// SecretSyncRuleStatus builds the status of the given SecretSyncRule from the outcome of a sync.
+func SecretSyncRuleStatus(rule *typesv1.SecretSyncRule, summary *SyncSummary) *typesv1.SecretSyncRuleStatus {
+	return SyncRuleStatus(&rule.Status, rule.Generation, summary)
+}
+
+// SyncRuleStatus builds the status of a rule with the given current status and generation from the outcome of a sync.
+func SyncRuleStatus(current *typesv1.SecretSyncRuleStatus, generation int64, summary *SyncSummary) *typesv1.SecretSyncRuleStatus {
+	status := current.DeepCopy()
+
+	failed := sets.NewString()
+	for _, failure := range summary.Failures {
+		failed.Insert(failure.Namespace)
+	}
+
+	status.ObservedGeneration = generation
+	status.Targets = summary.Targets
+	status.SyncedNamespaces = sets.NewString(summary.Synced...).Difference(failed).List()
+	status.Synced = len(status.SyncedNamespaces)
+	status.RefusedNamespaces = sets.NewString(summary.RefusedNamespaces...).List()
+
+	status.Failures = append([]typesv1.NamespaceFailure(nil), summary.Failures...)
+	sort.SliceStable(status.Failures, func(i, j int) bool { return status.Failures[i].Namespace < status.Failures[j].Namespace })
+
+	status.Conflicts = sortedConflicts(summary.Conflicts)
+
+	status.Clusters = nil
+	for _, cluster := range summary.Clusters {
+		status.Clusters = append(status.Clusters, ClusterStatus(cluster))
+	}
+	sort.SliceStable(status.Clusters, func(i, j int) bool { return status.Clusters[i].Name < status.Clusters[j].Name })
+
+	setCondition := func(conditionType string, ok bool, reason, message string) {
+		condition := metav1.Condition{
+			Type:               conditionType,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: generation,
+			Reason:             reason,
+			Message:            message,
+		}
+		if ok {
+			condition.Status = metav1.ConditionTrue
+		}
+		meta.SetStatusCondition(&status.Conditions, condition)
+	}
+
+	sourceMessage := fmt.Sprintf("found %d sources", summary.Sources)
+	switch {
+	case summary.InvalidSource != "":
+		sourceMessage = summary.InvalidSource
+	case len(summary.MissingSources) > 0:
+		sourceMessage = fmt.Sprintf("sources do not exist: %s", strings.Join(summary.MissingSources, ", "))
+	case summary.Sources == 0:
+		sourceMessage = "no sources match the rule"
+	}
+
+	if summary.SourceFound() {
+		setCondition(typesv1.ConditionSourceFound, true, "SourceFound", sourceMessage)
+	} else {
+		setCondition(typesv1.ConditionSourceFound, false, "SourceNotFound", sourceMessage)
+	}
+
+	failedClusters := summary.FailedClusters()
+	synced := len(summary.Failures) == 0 && len(failedClusters) == 0
+	progress := fmt.Sprintf("synced to %d of %d targeted namespaces", status.Synced, status.Targets)
+	if synced {
+		setCondition(typesv1.ConditionSynced, true, "Synced", progress)
+		setCondition(typesv1.ConditionDegraded, false, "Synced", progress)
+	} else {
+		degraded := fmt.Sprintf("failed to sync to %d namespaces", len(summary.Failures))
+		if len(failedClusters) > 0 {
+			degraded += fmt.Sprintf(" and remote clusters %s", strings.Join(failedClusters, ", "))
+		}
+		setCondition(typesv1.ConditionSynced, false, "SyncFailed", progress)
+		setCondition(typesv1.ConditionDegraded, true, "SyncFailed", degraded)
+	}
+
+	if blocked := summary.BlockedNamespaces(); len(blocked) > 0 {
+		setCondition(typesv1.ConditionConflict, true, "NotManaged", fmt.Sprintf("unmanaged copies block syncing to %s", strings.Join(blocked, ", ")))
+	} else {
+		setCondition(typesv1.ConditionConflict, false, "NoConflict", "no unmanaged copies block syncing")
+	}
+
+	switch {
+	case !summary.SourceFound():
+		setCondition(typesv1.ConditionReady, false, "SourceNotFound", sourceMessage)
+	case !synced:
+		setCondition(typesv1.ConditionReady, false, "SyncFailed", progress)
+	default:
+		setCondition(typesv1.ConditionReady, true, "Synced", progress)
+	}
+
+	return status
+}
+
+// ClusterStatus builds the status of a remote cluster from the outcome of syncing to it.
+func ClusterStatus(summary *ClusterSummary) typesv1.ClusterStatus {
+	failed := sets.NewString()
+	for _, failure := range summary.Failures {
+		failed.Insert(failure.Namespace)
+	}
+
+	status := typesv1.ClusterStatus{
+		Name:              summary.Name,
+		Secret:            summary.Secret,
+		Key:               summary.Key,
+		Connected:         summary.Connected,
+		Message:           summary.Error,
+		Targets:           summary.Targets,
+		SyncedNamespaces:  sets.NewString(summary.Synced...).Difference(failed).List(),
+		RefusedNamespaces: sets.NewString(summary.RefusedNamespaces...).List(),
+		Failures:          append([]typesv1.NamespaceFailure(nil), summary.Failures...),
+		Conflicts:         sortedConflicts(summary.Conflicts),
+	}
+	status.Synced = len(status.SyncedNamespaces)
+	sort.SliceStable(status.Failures, func(i, j int) bool { return status.Failures[i].Namespace < status.Failures[j].Namespace })
+
+	return status
+}
+
+// sortedConflicts returns a copy of the given conflicts sorted by namespace and name.
+func sortedConflicts(conflicts []typesv1.NamespaceConflict) []typesv1.NamespaceConflict {
+	sorted := append([]typesv1.NamespaceConflict(nil), conflicts...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	return sorted
+}
+
+// StatusesAreEqual compares two rule statuses, ignoring the last sync time.
+func StatusesAreEqual(a, b *typesv1.SecretSyncRuleStatus) bool {
+	aCopy := a.DeepCopy()
+	bCopy := b.DeepCopy()
+
+	aCopy.LastSyncTime = nil
+	bCopy.LastSyncTime = nil
+
+	return equality.Semantic.DeepEqual(aCopy, bCopy)
+}
@@ -0,0 +1,69 @@
+package client_test
+
+import (
+	"errors"
+	"testing"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	pkg "github.com/alehechka/kube-secret-sync/client"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_SecretSyncRuleStatus_Ready(t *testing.T) {
+	summary := &pkg.SyncSummary{Sources: 1, Targets: 2}
+	summary.Succeeded(keyTestNamespace, pkg.SyncActionCreated)
+	summary.Succeeded(keyExcludedNamespace, pkg.SyncActionNone)
+
+	status := pkg.SecretSyncRuleStatus(testSecretSyncRule, summary)
+
+	assert.Equal(t, 2, status.Synced)
+	assert.Equal(t, []string{keyExcludedNamespace, keyTestNamespace}, status.SyncedNamespaces)
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, typesv1.ConditionReady))
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, typesv1.ConditionSynced))
+	assert.True(t, meta.IsStatusConditionFalse(status.Conditions, typesv1.ConditionDegraded))
+}
+
+func Test_SecretSyncRuleStatus_Degraded(t *testing.T) {
+	summary := &pkg.SyncSummary{Sources: 1, Targets: 2}
+	summary.Succeeded(keyTestNamespace, pkg.SyncActionCreated)
+	summary.Failed(keyExcludedNamespace, "", errors.New("boom"))
+
+	status := pkg.SecretSyncRuleStatus(testSecretSyncRule, summary)
+
+	assert.Equal(t, 1, status.Synced)
+	assert.Equal(t, 1, len(status.Failures))
+	assert.Equal(t, pkg.ReasonSyncFailed, status.Failures[0].Reason)
+	assert.True(t, meta.IsStatusConditionFalse(status.Conditions, typesv1.ConditionReady))
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, typesv1.ConditionDegraded))
+}
+
+func Test_SecretSyncRuleStatus_SourceNotFound(t *testing.T) {
+	status := pkg.SecretSyncRuleStatus(testSecretSyncRule, new(pkg.SyncSummary))
+
+	assert.True(t, meta.IsStatusConditionFalse(status.Conditions, typesv1.ConditionSourceFound))
+	assert.True(t, meta.IsStatusConditionFalse(status.Conditions, typesv1.ConditionReady))
+}
+
+func Test_StatusesAreEqual_IgnoresLastSyncTime(t *testing.T) {
+	now := metav1.Now()
+	a := &typesv1.SecretSyncRuleStatus{Synced: 1}
+	b := &typesv1.SecretSyncRuleStatus{Synced: 1, LastSyncTime: &now}
+
+	assert.True(t, pkg.StatusesAreEqual(a, b))
+}
+
+func Test_SyncSecretSyncRule_UpdatesStatus(t *testing.T) {
+	client := InitializeTestClientset(defaultNamespace, testNamespace, defaultSecret, testSecretSyncRule)
+
+	err := client.SyncSecretSyncRule(keyTestSecretSyncRule)
+	assert.NoError(t, err)
+
+	rule, err := client.KubeSecretSyncClientset.SecretSyncRules().Get(client.Context, keyTestSecretSyncRule, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rule.Status.Targets)
+	assert.Equal(t, []string{keyTestNamespace}, rule.Status.SyncedNamespaces)
+	assert.NotNil(t, rule.Status.LastSyncTime)
+	assert.True(t, meta.IsStatusConditionTrue(rule.Status.Conditions, typesv1.ConditionReady))
+}
@@ -0,0 +1,239 @@
+package client
+
+import (
+	"errors"
+
+	typesv1 "github.com/alehechka/kube-secret-sync/api/types/v1"
+	"github.com/alehechka/kube-secret-sync/constants"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// SyncAction describes the change made to a synced Secret.
+type SyncAction string
+
+const (
+	// SyncActionNone is returned when a synced Secret was already up to date.
+	SyncActionNone SyncAction = ""
+	// SyncActionCreated is returned when a missing synced Secret was created.
+	SyncActionCreated SyncAction = "created"
+	// SyncActionUpdated is returned when a synced Secret was rewritten.
+	SyncActionUpdated SyncAction = "updated"
+	// SyncActionDeleted is returned when a synced Secret was removed.
+	SyncActionDeleted SyncAction = "deleted"
+	// SyncActionSkipped is returned when an existing unmanaged Secret was left untouched.
+	SyncActionSkipped SyncAction = "skipped"
+	// SyncActionOverwritten is returned when an existing unmanaged Secret was replaced by the synced copy.
+	SyncActionOverwritten SyncAction = "overwritten"
+	// SyncActionAdopted is returned when an existing unmanaged Secret with the content of the synced copy was taken over.
+	SyncActionAdopted SyncAction = "adopted"
+	// SyncActionMerged is returned when the keys missing from an existing unmanaged Secret were added to it.
+	SyncActionMerged SyncAction = "merged"
+	// SyncActionConflict is returned when an existing unmanaged Secret was left untouched and blocks syncing.
+	SyncActionConflict SyncAction = "conflict"
+	// SyncActionOrphaned is returned when a synced Secret was released from kube-secret-sync instead of being removed.
+	SyncActionOrphaned SyncAction = "orphaned"
+)
+
+// ReasonSecretNotManaged is the failure reason recorded when an unmanaged Secret blocks syncing to a Namespace.
+const ReasonSecretNotManaged = "SecretNotManaged"
+
+// ReasonConfigMapNotManaged is the failure reason recorded when an unmanaged ConfigMap blocks syncing to a Namespace.
+const ReasonConfigMapNotManaged = "ConfigMapNotManaged"
+
+// ReasonResourceNotManaged is the failure reason recorded when an unmanaged resource blocks syncing to a Namespace.
+const ReasonResourceNotManaged = "ResourceNotManaged"
+
+// ReasonInvalidTargetName is the failure reason recorded when the target name template cannot be rendered for a Namespace.
+const ReasonInvalidTargetName = "InvalidTargetName"
+
+// ReasonTargetNameConflict is the failure reason recorded when several source Secrets render to the same target name in a Namespace.
+const ReasonTargetNameConflict = "TargetNameConflict"
+
+// ReasonInvalidTemplate is the failure reason recorded when the data template cannot be rendered for a Namespace.
+const ReasonInvalidTemplate = "InvalidTemplate"
+
+// ReasonSyncFailed is the failure reason recorded when the API server rejected a sync without a more specific reason.
+const ReasonSyncFailed = "SyncFailed"
+
+// ConflictResolutions maps the actions taken on existing unmanaged Secrets to the resolution recorded in the status of the rule.
+var ConflictResolutions = map[SyncAction]string{
+	SyncActionSkipped:     "Skipped",
+	SyncActionOverwritten: "Overwritten",
+	SyncActionAdopted:     "Adopted",
+	SyncActionMerged:      "Merged",
+	SyncActionConflict:    "Failed",
+}
+
+// SyncSummary records the outcome of reconciling a SecretSyncRule, ConfigMapSyncRule or ResourceSyncRule.
+type SyncSummary struct {
+	Created  int
+	Updated  int
+	Deleted  int
+	Orphaned int
+
+	Sources           int
+	MissingSources    []string
+	InvalidSource     string
+	Targets           int
+	Synced            []string
+	RefusedNamespaces []string
+	Failures          []typesv1.NamespaceFailure
+	Conflicts         []typesv1.NamespaceConflict
+	Clusters          []*ClusterSummary
+}
+
+// ClusterSummary records the outcome of syncing a SecretSyncRule to a remote cluster.
+type ClusterSummary struct {
+	SyncSummary
+
+	Name      string
+	Secret    *typesv1.Secret
+	Key       string
+	Connected bool
+	Error     string
+}
+
+// Cluster adds the summary of the given remote cluster and returns it.
+func (summary *SyncSummary) Cluster(cluster typesv1.Cluster) *ClusterSummary {
+	clusterSummary := &ClusterSummary{Name: cluster.Name, Secret: &cluster.Secret, Key: cluster.Key}
+	summary.Clusters = append(summary.Clusters, clusterSummary)
+	return clusterSummary
+}
+
+// FailedClusters returns the names of the remote clusters that could not be reached or synced to every targeted Namespace.
+func (summary *SyncSummary) FailedClusters() (names []string) {
+	for _, cluster := range summary.Clusters {
+		if cluster.Error != "" || len(cluster.Failures) > 0 {
+			names = append(names, cluster.Name)
+		}
+	}
+
+	return
+}
+
+// Add records the given action in the summary.
+func (summary *SyncSummary) Add(action SyncAction) {
+	switch action {
+	case SyncActionCreated:
+		summary.Created++
+	case SyncActionUpdated, SyncActionOverwritten, SyncActionAdopted, SyncActionMerged:
+		summary.Updated++
+	case SyncActionDeleted:
+		summary.Deleted++
+	case SyncActionOrphaned:
+		summary.Orphaned++
+	}
+}
+
+// Succeeded records that the source Secret is synced to the given Namespace.
+func (summary *SyncSummary) Succeeded(namespace string, action SyncAction) {
+	summary.Add(action)
+	summary.Synced = append(summary.Synced, namespace)
+}
+
+// Refused records that the given Namespaces are matched by the rule but refuse its copies.
+func (summary *SyncSummary) Refused(namespaces []*v1.Namespace) {
+	for _, namespace := range namespaces {
+		summary.RefusedNamespaces = append(summary.RefusedNamespaces, namespace.Name)
+	}
+}
+
+// Failed records that the source Secret could not be synced to the given Namespace.
+func (summary *SyncSummary) Failed(namespace, reason string, err error) {
+	if reason == "" {
+		reason = failureReason(err)
+	}
+
+	failure := typesv1.NamespaceFailure{Namespace: namespace, Reason: reason}
+	if err != nil {
+		failure.Message = err.Error()
+	}
+
+	summary.Failures = append(summary.Failures, failure)
+}
+
+// Conflicted records how the existing unmanaged Secret with the given name in the given Namespace was handled.
+func (summary *SyncSummary) Conflicted(namespace, name string, action SyncAction) {
+	summary.Conflicts = append(summary.Conflicts, typesv1.NamespaceConflict{Namespace: namespace, Name: name, Resolution: ConflictResolutions[action]})
+}
+
+// BlockedNamespaces returns the Namespaces in which an existing object that the rule does not manage blocks syncing,
+// prefixed with the name of their cluster for remote clusters.
+func (summary *SyncSummary) BlockedNamespaces() (namespaces []string) {
+	for _, failure := range summary.Failures {
+		switch failure.Reason {
+		case ReasonSecretNotManaged, ReasonConfigMapNotManaged, ReasonResourceNotManaged:
+			namespaces = append(namespaces, failure.Namespace)
+		}
+	}
+
+	for _, cluster := range summary.Clusters {
+		for _, namespace := range cluster.BlockedNamespaces() {
+			namespaces = append(namespaces, cluster.Name+"/"+namespace)
+		}
+	}
+
+	return sets.NewString(namespaces...).List()
+}
+
+// SourceFound reports whether every referenced source Secret exists and at least one source Secret was found.
+func (summary *SyncSummary) SourceFound() bool {
+	return summary.Sources > 0 && len(summary.MissingSources) == 0 && summary.InvalidSource == ""
+}
+
+// Changed reports whether any synced Secret was changed, including the copies in remote clusters.
+func (summary *SyncSummary) Changed() bool {
+	for _, cluster := range summary.Clusters {
+		if cluster.Changed() {
+			return true
+		}
+	}
+
+	return summary.Created+summary.Updated+summary.Deleted+summary.Orphaned > 0
+}
+
+// Log writes the summary to the given logger if any synced Secret was changed.
+func (summary *SyncSummary) Log(logger *log.Entry) {
+	if !summary.Changed() {
+		logger.Debugf("all synced copies are up to date")
+		return
+	}
+
+	created, updated, deleted, orphaned := summary.Created, summary.Updated, summary.Deleted, summary.Orphaned
+	for _, cluster := range summary.Clusters {
+		created, updated, deleted, orphaned = created+cluster.Created, updated+cluster.Updated, deleted+cluster.Deleted, orphaned+cluster.Orphaned
+	}
+
+	logger.WithFields(log.Fields{
+		"created":  created,
+		"updated":  updated,
+		"deleted":  deleted,
+		"orphaned": orphaned,
+	}).Infof("repaired synced copies")
+}
+
+// IsRuleError reports whether the given error is caused by the rule itself, so that retrying the sync cannot fix it.
+func IsRuleError(err error) bool {
+	return errors.Is(err, constants.ErrInvalidTargetName) || errors.Is(err, constants.ErrInvalidTemplate) ||
+		errors.Is(err, constants.ErrResourceNotNamespaced) || errors.Is(err, constants.ErrCrossNamespaceSource) ||
+		errors.Is(err, constants.ErrKubeconfigNotFound) || errors.Is(err, constants.ErrInvalidKubeconfig) ||
+		errors.Is(err, constants.ErrNamespacedExternalSource) || errors.Is(err, constants.ErrNamespacedRemoteCluster) || errors.Is(err, constants.ErrUnknownSourceProvider) ||
+		errors.Is(err, constants.ErrInvalidSource)
+}
+
+func failureReason(err error) string {
+	switch {
+	case errors.Is(err, constants.ErrInvalidTargetName):
+		return ReasonInvalidTargetName
+	case errors.Is(err, constants.ErrInvalidTemplate):
+		return ReasonInvalidTemplate
+	}
+
+	if reason := apierrors.ReasonForError(err); reason != "" {
+		return string(reason)
+	}
+	return ReasonSyncFailed
+}
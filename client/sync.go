@@ -1,7 +1,12 @@
 package client
 
 import (
+	"context"
+	"time"
+
+	"github.com/alehechka/kube-secret-sync/constants"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
 )
 
 // SyncSecrets syncs Secrets across all selected Namespaces
@@ -14,45 +19,40 @@ func SyncSecrets(config *SyncConfig) (err error) {
 		return err
 	}
 
-	defer client.SecretWatcher.Stop()
-	defer client.NamespaceWatcher.Stop()
-	defer client.SecretSyncRuleWatcher.Stop()
-
-	for {
-		select {
-		case secretEvent, ok := <-client.SecretWatcher.ResultChan():
-			if !ok {
-				log.Debug("Secret watcher timed out, restarting now.")
-				if err := client.StartSecretWatcher(); err != nil {
-					return err
-				}
-				defer client.SecretWatcher.Stop()
-				continue
-			}
-			client.SecretEventHandler(secretEvent)
-		case namespaceEvent, ok := <-client.NamespaceWatcher.ResultChan():
-			if !ok {
-				log.Debug("Namespace watcher timed out, restarting now.")
-				if err := client.StartNamespaceWatcher(); err != nil {
-					return err
-				}
-				defer client.NamespaceWatcher.Stop()
-				continue
-			}
-			client.NamespaceEventHandler(namespaceEvent)
-		case secretSyncRuleEvent, ok := <-client.SecretSyncRuleWatcher.ResultChan():
-			if !ok {
-				log.Debug("SecretSyncRule watcher timed out, restarting now.")
-				if err := client.StartSecretSyncRuleWatcher(); err != nil {
-					return err
-				}
-				defer client.SecretSyncRuleWatcher.Stop()
-				continue
-			}
-			client.SecretSyncRuleEventHandler(secretSyncRuleEvent)
-		case s := <-client.SignalChannel:
-			log.Infof("Shutting down from signal: %s", s)
+	ctx, cancel := context.WithCancel(client.Context)
+	defer cancel()
+	client.Context = ctx
+
+	go func() {
+		s := <-client.SignalChannel
+		log.Infof("Shutting down from signal: %s", s)
+		cancel()
+	}()
+
+	if config.LeaderElect {
+		return client.RunWithLeaderElection(ctx)
+	}
+
+	return client.Run(ctx)
+}
+
+// Run starts the informers and processes the work queue until the context is cancelled.
+func (client *Client) Run(ctx context.Context) error {
+	defer client.Queue.ShutDown()
+
+	if !client.StartInformers(ctx.Done()) {
+		if ctx.Err() != nil {
 			return nil
 		}
+		return constants.ErrCacheSync
 	}
+
+	client.MigrateOwnerReferences()
+	client.WatchSourceProviders(ctx)
+
+	log.Info("Informer caches synced, starting worker")
+	go wait.UntilWithContext(ctx, client.RunWorker, time.Second)
+
+	<-ctx.Done()
+	return nil
 }
@@ -1,37 +0,0 @@
-package client
-
-import (
-	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-)
-
-func (client *Client) InitializeWatchers() (err error) {
-	if err := client.StartSecretWatcher(); err != nil {
-		return err
-	}
-
-	if err := client.StartNamespaceWatcher(); err != nil {
-		return err
-	}
-
-	if err := client.StartSecretSyncRuleWatcher(); err != nil {
-		return err
-	}
-
-	return
-}
-
-func (client *Client) StartSecretWatcher() (err error) {
-	client.SecretWatcher, err = client.DefaultClientset.CoreV1().Secrets(v1.NamespaceAll).Watch(client.Context, metav1.ListOptions{})
-	return
-}
-
-func (client *Client) StartNamespaceWatcher() (err error) {
-	client.NamespaceWatcher, err = client.DefaultClientset.CoreV1().Namespaces().Watch(client.Context, metav1.ListOptions{})
-	return
-}
-
-func (client *Client) StartSecretSyncRuleWatcher() (err error) {
-	client.SecretSyncRuleWatcher, err = client.KubeSecretSyncClientset.SecretSyncRules().Watch(client.Context, metav1.ListOptions{})
-	return
-}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"path/filepath"
+	"time"
 
 	"github.com/alehechka/kube-secret-sync/client"
 	log "github.com/sirupsen/logrus"
@@ -15,6 +16,16 @@ const (
 	outOfClusterFlag = "out-of-cluster"
 	kubeconfigFlag   = "kubeconfig"
 	podNamespace     = "pod-namespace"
+	resyncFlag       = "resync-interval"
+
+	fileSourceDirFlag          = "file-source-dir"
+	fileSourcePollIntervalFlag = "file-source-poll-interval"
+
+	leaderElectFlag   = "leader-elect"
+	leaseNameFlag     = "leader-elect-lease-name"
+	leaseDurationFlag = "leader-elect-lease-duration"
+	renewDeadlineFlag = "leader-elect-renew-deadline"
+	retryPeriodFlag   = "leader-elect-retry-period"
 )
 
 func kubeconfig() *cli.StringFlag {
@@ -38,6 +49,7 @@ var startFlags = []cli.Flag{
 	&cli.StringFlag{
 		Name:    podNamespace,
 		Usage:   "Specifies the namespace that current application pod is running in.",
+		Value:   "kube-secret-sync",
 		EnvVars: []string{"POD_NAMESPACE"},
 	},
 	&cli.BoolFlag{
@@ -45,6 +57,53 @@ var startFlags = []cli.Flag{
 		Usage:   "Will use the default ~/.kube/config file on the local machine to connect to the cluster externally.",
 		Aliases: []string{"local"},
 	},
+	&cli.DurationFlag{
+		Name:    resyncFlag,
+		Usage:   "Interval at which every SecretSyncRule is fully reconciled to repair drifted secrets (0 disables).",
+		Value:   5 * time.Minute,
+		EnvVars: []string{"RESYNC_INTERVAL"},
+	},
+	&cli.StringFlag{
+		Name:    fileSourceDirFlag,
+		Usage:   "Directory that the file source provider reads external sources from (empty disables the provider).",
+		EnvVars: []string{"FILE_SOURCE_DIR"},
+	},
+	&cli.DurationFlag{
+		Name:    fileSourcePollIntervalFlag,
+		Usage:   "Interval at which the file source provider checks its files for changes.",
+		Value:   10 * time.Second,
+		EnvVars: []string{"FILE_SOURCE_POLL_INTERVAL"},
+	},
+	&cli.BoolFlag{
+		Name:    leaderElectFlag,
+		Usage:   "Elect a leader through a Lease in the pod namespace so that only one replica syncs at a time (off by default when running out of cluster).",
+		Value:   true,
+		EnvVars: []string{"LEADER_ELECT"},
+	},
+	&cli.StringFlag{
+		Name:    leaseNameFlag,
+		Usage:   "Name of the Lease used for leader election.",
+		Value:   "kube-secret-sync",
+		EnvVars: []string{"LEADER_ELECT_LEASE_NAME"},
+	},
+	&cli.DurationFlag{
+		Name:    leaseDurationFlag,
+		Usage:   "Duration that standby replicas wait before attempting to take over an unrenewed Lease.",
+		Value:   15 * time.Second,
+		EnvVars: []string{"LEADER_ELECT_LEASE_DURATION"},
+	},
+	&cli.DurationFlag{
+		Name:    renewDeadlineFlag,
+		Usage:   "Duration that the leader retries renewing its Lease before giving up leadership.",
+		Value:   10 * time.Second,
+		EnvVars: []string{"LEADER_ELECT_RENEW_DEADLINE"},
+	},
+	&cli.DurationFlag{
+		Name:    retryPeriodFlag,
+		Usage:   "Duration between leader election attempts.",
+		Value:   2 * time.Second,
+		EnvVars: []string{"LEADER_ELECT_RETRY_PERIOD"},
+	},
 }
 
 func startKubeSecretSync(ctx *cli.Context) (err error) {
@@ -52,11 +111,28 @@ func startKubeSecretSync(ctx *cli.Context) (err error) {
 		log.SetLevel(log.DebugLevel)
 	}
 
+	// a local process is the only replica, so it does not elect a leader unless asked to
+	leaderElect := ctx.Bool(leaderElectFlag)
+	if ctx.Bool(outOfClusterFlag) && !ctx.IsSet(leaderElectFlag) {
+		leaderElect = false
+	}
+
 	return client.SyncSecrets(&client.SyncConfig{
 		PodNamespace: ctx.String(podNamespace),
 
 		OutOfCluster: ctx.Bool(outOfClusterFlag),
 		KubeConfig:   ctx.String(kubeconfigFlag),
+
+		ResyncInterval: ctx.Duration(resyncFlag),
+
+		FileSourceDirectory:    ctx.String(fileSourceDirFlag),
+		FileSourcePollInterval: ctx.Duration(fileSourcePollIntervalFlag),
+
+		LeaderElect:   leaderElect,
+		LeaseName:     ctx.String(leaseNameFlag),
+		LeaseDuration: ctx.Duration(leaseDurationFlag),
+		RenewDeadline: ctx.Duration(renewDeadlineFlag),
+		RetryPeriod:   ctx.Duration(retryPeriodFlag),
 	})
 }
 
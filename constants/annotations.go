@@ -8,3 +8,36 @@ const ManagedByAnnotationValue = "kube-secret-sync"
 
 // LastAppliedConfigurationAnnotationKey is an annotation created by Kubernetes to keep track of last config
 const LastAppliedConfigurationAnnotationKey = "kubectl.kubernetes.io/last-applied-configuration"
+
+// DeletionPolicyAnnotationKey is an annotation key appended to synced secrets to record what happens to them when their rule is deleted,
+// since the rule itself is no longer available at that point. It is only set for policies other than Delete.
+const DeletionPolicyAnnotationKey = "kube-secret-sync.io/deletion-policy"
+
+// RefuseSyncAnnotationKey is an annotation key on target namespaces that refuses copies from every rule when set to true.
+const RefuseSyncAnnotationKey = "kube-secret-sync.io/refuse"
+
+// AcceptRulesAnnotationKey is an annotation key on target namespaces listing the only rules, separated by commas, whose copies are accepted.
+// Rules living in a namespace are listed as <namespace>/<name>.
+const AcceptRulesAnnotationKey = "kube-secret-sync.io/accept-rules"
+
+// RequireOptInAnnotationKey is an annotation key on target namespaces that, when set to true,
+// only accepts copies from rules that include the namespace by its name.
+const RequireOptInAnnotationKey = "kube-secret-sync.io/require-opt-in"
+
+// SourceNamespaceAnnotationKey is an annotation key appended to synced secrets to record the namespace of the Secret they were copied from.
+const SourceNamespaceAnnotationKey = "kube-secret-sync.io/source-namespace"
+
+// SourceNameAnnotationKey is an annotation key appended to synced secrets to record the name of the Secret they were copied from.
+const SourceNameAnnotationKey = "kube-secret-sync.io/source-name"
+
+// SourceResourceVersionAnnotationKey is an annotation key appended to synced secrets to record the resourceVersion of the Secret
+// they were last written from. Source changes that leave the copy unchanged do not update it.
+const SourceResourceVersionAnnotationKey = "kube-secret-sync.io/source-resource-version"
+
+// RuleAnnotationKey is an annotation key appended to synced secrets to record the rule that wrote them.
+// Rules living in a namespace are recorded as <namespace>/<name>.
+const RuleAnnotationKey = "kube-secret-sync.io/rule"
+
+// ContentHashAnnotationKey is an annotation key appended to synced secrets to record a hash of their synced content,
+// which lets the controller skip updates that would not change the copy.
+const ContentHashAnnotationKey = "kube-secret-sync.io/content-hash"
@@ -16,3 +16,60 @@ var ErrNotIncludedNamespace = errors.New("namespaces was not marked as included"
 
 // ErrSecretsNamespace is the error returned when an event for the Namespace that secrets are synced from is triggered.
 var ErrSecretsNamespace = errors.New("namespace is used to sync secrets from")
+
+// ErrCacheSync is the error returned when the informer caches fail to sync on startup.
+var ErrCacheSync = errors.New("failed to wait for caches to sync")
+
+// ErrPodNamespaceRequired is the error returned when leader election is enabled without a pod namespace to store the Lease in.
+var ErrPodNamespaceRequired = errors.New("pod namespace is required for leader election")
+
+// ErrLeaderElectionLost is the error returned when the controller loses its leader Lease while still running.
+var ErrLeaderElectionLost = errors.New("leader election lost")
+
+// ErrSecretNotManaged is the error recorded when an existing Secret is not managed by kube-secret-sync and will not be force updated.
+var ErrSecretNotManaged = errors.New("existing secret is not managed by kube-secret-sync and force is not enabled")
+
+// ErrInvalidTargetName is the error returned when the target name template of a SecretSyncRule cannot be rendered to a valid Secret name.
+var ErrInvalidTargetName = errors.New("invalid target name")
+
+// ErrTargetNameConflict is the error recorded when several source Secrets of a SecretSyncRule render to the same target name.
+var ErrTargetNameConflict = errors.New("target name is already used by another source secret")
+
+// ErrInvalidTemplate is the error returned when the data template of a SecretSyncRule cannot be rendered.
+var ErrInvalidTemplate = errors.New("invalid template")
+
+// ErrSourceNamespaceUnknown is the error returned when a template references the namespace of a source Secret that has none, such as a merged Secret.
+var ErrSourceNamespaceUnknown = errors.New("source secret has no namespace")
+
+// ErrConfigMapNotManaged is the error recorded when an existing ConfigMap is not managed by kube-secret-sync and will not be force updated.
+var ErrConfigMapNotManaged = errors.New("existing configmap is not managed by kube-secret-sync and force is not enabled")
+
+// ErrResourceNotManaged is the error recorded when an existing resource is not managed by kube-secret-sync and will not be force updated.
+var ErrResourceNotManaged = errors.New("existing resource is not managed by kube-secret-sync and force is not enabled")
+
+// ErrResourceNotNamespaced is the error returned when a ResourceSyncRule references a cluster scoped kind.
+var ErrResourceNotNamespaced = errors.New("resource kind is not namespaced")
+
+// ErrCrossNamespaceSource is the error returned when a NamespacedSecretSyncRule references a Secret outside of its own namespace.
+var ErrCrossNamespaceSource = errors.New("namespaced rules can only sync secrets from their own namespace")
+
+// ErrKubeconfigNotFound is the error returned when the Secret or key holding the kubeconfig of a remote cluster does not exist.
+var ErrKubeconfigNotFound = errors.New("kubeconfig not found")
+
+// ErrInvalidKubeconfig is the error returned when the kubeconfig of a remote cluster cannot be loaded.
+var ErrInvalidKubeconfig = errors.New("invalid kubeconfig")
+
+// ErrNamespacedExternalSource is the error returned when a NamespacedSecretSyncRule references an external source.
+var ErrNamespacedExternalSource = errors.New("namespaced rules cannot sync from external sources")
+
+// ErrNamespacedRemoteCluster is the error returned when a NamespacedSecretSyncRule references a remote cluster.
+var ErrNamespacedRemoteCluster = errors.New("namespaced rules cannot sync to remote clusters")
+
+// ErrUnknownSourceProvider is the error returned when an external source references a source provider that is not configured.
+var ErrUnknownSourceProvider = errors.New("unknown source provider")
+
+// ErrSourceNotFound is the error returned by source providers when the data of an external source does not exist.
+var ErrSourceNotFound = errors.New("source not found")
+
+// ErrInvalidSource is the error returned by source providers when the data of an external source cannot be read.
+var ErrInvalidSource = errors.New("invalid source")
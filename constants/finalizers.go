@@ -0,0 +1,5 @@
+package constants
+
+// FinalizerName is the finalizer added to rules so that their synced copies are cleaned up before the rule is removed,
+// even if the rule is deleted while kube-secret-sync is not running.
+const FinalizerName = "kube-secret-sync.io/cleanup"
@@ -0,0 +1,10 @@
+package constants
+
+// RuleLabelKey is a label key appended to kube-secret-sync managed secrets to identify the SecretSyncRule that created them.
+const RuleLabelKey = "kube-secret-sync.io/rule"
+
+// RuleNamespaceLabelKey is a label key appended to secrets synced by a NamespacedSecretSyncRule to identify the namespace of the rule.
+const RuleNamespaceLabelKey = "kube-secret-sync.io/rule-namespace"
+
+// RuleKindLabelKey is a label key appended to kube-secret-sync managed copies to identify the kind of the rule that created them.
+const RuleKindLabelKey = "kube-secret-sync.io/rule-kind"